@@ -0,0 +1,154 @@
+package Goij
+
+import (
+	"fmt"
+	"github.com/j7mbo/goij/src/Logger"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+/* Finds a DefineArg() definition by function name, or by the name of the type the function returns. */
+func (ij *injector) findArgDefinition(object interface{}, objectType reflect.Type, position int) (interface{}, bool) {
+	if len(ij.argDefinitions) == 0 {
+		return nil, false
+	}
+
+	names := functionNames(object)
+
+	if objectType.NumOut() > 0 {
+		returnType := objectType.Out(0)
+
+		if returnType.Kind() == reflect.Ptr {
+			returnType = returnType.Elem()
+		}
+
+		names = append(names, fmt.Sprintf("%s.%s", returnType.PkgPath(), returnType.Name()), returnType.Name())
+	}
+
+	for _, name := range names {
+		if definition, found := ij.argDefinitions[name][position]; found {
+			return definition, true
+		}
+	}
+
+	return nil, false
+}
+
+/*
+Finds a definition for a function argument, in the same order of specificity as for fields: the definitions for the
+function, the type and name of the argument, the global definitions and then the type of the argument.
+
+Argument names cannot be retrieved with reflection, so only the type is used unless the generator registered the names.
+*/
+func (ij *injector) findNamedArgDefinition(object interface{}, position int) (interface{}, Source, bool) {
+	argType := getElem(object).Type().In(position)
+	argName := ij.findArgName(object, position)
+
+	if argName != "" {
+		for _, name := range functionNames(object) {
+			if definition, found := ij.definitions[name][argName]; found {
+				return definition, SourceDefinition, true
+			}
+		}
+
+		if definition, found := ij.findTypeDefinition(argType, argName); found {
+			return definition, SourceNamedTypeDefinition, true
+		}
+
+		if definition, found := ij.globalDefinitions[argName]; found {
+			if _, err := ij.converter.Convert(definition, argType); err == nil {
+				return definition, SourceGlobalDefinition, true
+			}
+		}
+	}
+
+	definition, found := ij.findTypeDefinition(argType, "")
+
+	return definition, SourceTypeDefinition, found
+}
+
+/* Finds the name of the argument at the given position, if the generator registered the names for the function. */
+func (ij *injector) findArgName(object interface{}, position int) string {
+	names := functionNames(object)
+
+	if len(names) == 0 {
+		return ""
+	}
+
+	argNames := ij.tr.FindFactoryArgumentNames(names[0])
+
+	if position >= len(argNames) {
+		return ""
+	}
+
+	return argNames[position]
+}
+
+/* The name of the argument at the given position for paths, like "dsn", or "arg0" if it's name was not registered. */
+func (ij *injector) argPathName(object interface{}, position int) string {
+	if name := ij.findArgName(object, position); name != "" {
+		return name
+	}
+
+	return fmt.Sprintf("arg%d", position)
+}
+
+/* Converts a user-provided definition to a value for the function argument at the given position, or panics. */
+func (ij *injector) toDefinedArgValue(
+	definition interface{}, source Source, object interface{}, position int,
+) reflect.Value {
+	objectType := getElem(object).Type()
+
+	value, err := ij.converter.Convert(definition, objectType.In(position))
+
+	if err != nil {
+		ij.fail(
+			newFailure(
+				IssueDefinitionMismatch,
+				"Definition for argument %d of function: %s could not be injected: %s", position, objectType, err.Error(),
+			),
+		)
+	}
+
+	/* Looking up the name of the argument is not free, so only do it when it's going to be used. */
+	if ij.logEnabled(Logger.LevelDebug) || ij.observed() {
+		argName := ij.argPathName(object, position)
+
+		ij.debug("Injecting definition", Logger.FieldName(argName), Logger.Type(definition), Logger.Source(source))
+		ij.emit(Event{Kind: EventDefinitionApplied, Field: argName, Type: objectType.In(position), Source: source})
+	}
+
+	return value
+}
+
+/*
+Returns the names a function can be referred to by, most specific first: "github.com/x/pkg.NewServer", "pkg.NewServer"
+and "NewServer". Closures have generated names like "pkg.main.func1", which are not going to be used by anyone.
+*/
+func functionNames(object interface{}) []string {
+	function := getElem(object)
+
+	if function.Kind() != reflect.Func || function.IsNil() {
+		return nil
+	}
+
+	runtimeFunc := runtime.FuncForPC(function.Pointer())
+
+	if runtimeFunc == nil {
+		return nil
+	}
+
+	fullName := runtimeFunc.Name()
+	names := []string{fullName}
+
+	if lastSlash := strings.LastIndex(fullName, "/"); lastSlash >= 0 {
+		names = append(names, fullName[lastSlash+1:])
+	}
+
+	if lastDot := strings.LastIndex(fullName, "."); lastDot >= 0 {
+		names = append(names, fullName[lastDot+1:])
+	}
+
+	return names
+}
@@ -0,0 +1,299 @@
+package Goij
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/j7mbo/goij/src/Logger"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+/*
+The JSON document read by LoadConfig(), for example:
+
+	{
+		"bindings":    { "AnInterface": "DepTwo" },
+		"definitions": { "Object": { "HostName": "http://www.github.com", "Port": 80 } },
+		"globals":     { "AFieldHere": "Hello World" },
+		"shared":      { "DBConfiguration": { "Hostname": "http://www.github.com", "Port": 80 } }
+	}
+*/
+type configuration struct {
+	/* Interface name to struct name, as with Bind(). */
+	Bindings map[string]string `json:"bindings"`
+
+	/* Struct name to field name to value, as with Define(). */
+	Definitions map[string]map[string]json.RawMessage `json:"definitions"`
+
+	/* Field name to value, as with DefineGlobal(). */
+	Globals map[string]json.RawMessage `json:"globals"`
+
+	/* Struct name to field name to value, the struct is initialised with these values and Share()d. */
+	Shared map[string]map[string]json.RawMessage `json:"shared"`
+}
+
+/* ConfigurationError lists every problem found in a configuration document, each prefixed with it's JSON path. */
+type ConfigurationError struct {
+	Problems []string
+}
+
+func (e *ConfigurationError) Error() string {
+	return "Unable to load configuration: " + strings.Join(e.Problems, "; ")
+}
+
+func (ij *injector) LoadConfig(reader io.Reader) error {
+	var config configuration
+
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&config); err != nil {
+		return &ConfigurationError{Problems: []string{err.Error()}}
+	}
+
+	var problems []string
+
+	/* Nothing is applied until the whole document is known to be valid. */
+	var apply []func()
+
+	addProblem := func(path string, format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf("%s: %s", path, fmt.Sprintf(format, args...)))
+	}
+
+	for _, interfaceName := range sortedKeys(config.Bindings) {
+		path := "bindings." + interfaceName
+		structName := config.Bindings[interfaceName]
+
+		if ij.tr.FindInterfaceType(interfaceName) == nil {
+			addProblem(path, "interface not found in type registry")
+
+			continue
+		}
+
+		if ij.tr.FindStructType(structName) == nil {
+			addProblem(path, "struct: '%s' not found in type registry", structName)
+
+			continue
+		}
+
+		interfaceName := interfaceName
+
+		apply = append(apply, func() { ij.Bind(interfaceName, structName) })
+	}
+
+	for _, structName := range sortedKeys(config.Definitions) {
+		structType := ij.findConfigStructType(structName)
+
+		if structType == nil {
+			addProblem("definitions."+structName, "struct not found in type registry")
+
+			continue
+		}
+
+		for _, fieldName := range sortedKeys(config.Definitions[structName]) {
+			path := fmt.Sprintf("definitions.%s.%s", structName, fieldName)
+
+			field, found := structType.FieldByName(fieldName)
+
+			if !found || field.PkgPath != "" {
+				addProblem(path, "no exported field with this name exists on struct: '%s'", structType)
+
+				continue
+			}
+
+			value, err := ij.decodeConfigValue(config.Definitions[structName][fieldName], field.Type)
+
+			if err != nil {
+				addProblem(path, "%s", err.Error())
+
+				continue
+			}
+
+			structName, fieldName := structName, fieldName
+
+			apply = append(apply, func() { ij.Define(structName, fieldName, value.Interface()) })
+		}
+	}
+
+	for _, fieldName := range sortedKeys(config.Globals) {
+		path := "globals." + fieldName
+
+		value, err := ij.decodeGlobalConfigValue(config.Globals[fieldName], fieldName)
+
+		if err != nil {
+			addProblem(path, "%s", err.Error())
+
+			continue
+		}
+
+		fieldName := fieldName
+
+		apply = append(apply, func() { ij.DefineGlobal(fieldName, value) })
+	}
+
+	for _, structName := range sortedKeys(config.Shared) {
+		structType := ij.findConfigStructType(structName)
+
+		if structType == nil {
+			addProblem("shared."+structName, "struct not found in type registry")
+
+			continue
+		}
+
+		obj := reflect.New(structType).Elem()
+
+		for _, fieldName := range sortedKeys(config.Shared[structName]) {
+			path := fmt.Sprintf("shared.%s.%s", structName, fieldName)
+
+			field, found := structType.FieldByName(fieldName)
+
+			if !found || field.PkgPath != "" {
+				addProblem(path, "no exported field with this name exists on struct: '%s'", structType)
+
+				continue
+			}
+
+			value, err := ij.decodeConfigValue(config.Shared[structName][fieldName], field.Type)
+
+			if err != nil {
+				addProblem(path, "%s", err.Error())
+
+				continue
+			}
+
+			obj.FieldByIndex(field.Index).Set(value)
+		}
+
+		apply = append(apply, func() { ij.Share(obj.Interface()) })
+	}
+
+	if len(problems) > 0 {
+		return &ConfigurationError{Problems: problems}
+	}
+
+	for _, applyFunc := range apply {
+		applyFunc()
+	}
+
+	ij.info("Loaded configuration", Logger.Any("applied", len(apply)))
+
+	return nil
+}
+
+/* Finds the reflected struct type for a struct name in the configuration. */
+func (ij *injector) findConfigStructType(structName string) reflect.Type {
+	structType := ij.tr.FindStructType(structName)
+
+	if structType == nil {
+		return nil
+	}
+
+	return reflect.TypeOf(structType)
+}
+
+/*
+Global definitions are matched on field name only, so the value is converted to the type of the registry struct fields
+with that name. As with DefineGlobal(), the global only has to be convertible to one of them: the fields it can't be
+converted to are skipped when injecting. If every field has the same type the converted value is defined, otherwise the
+JSON value is defined as it is and converted to each field when it is injected.
+*/
+func (ij *injector) decodeGlobalConfigValue(raw json.RawMessage, fieldName string) (interface{}, error) {
+	fieldTypes := ij.findGlobalFieldTypes(fieldName)
+
+	if len(fieldTypes) == 0 {
+		return nil, fmt.Errorf("no struct in the type registry has an exported field with this name")
+	}
+
+	var converted []reflect.Value
+
+	for _, fieldType := range fieldTypes {
+		if value, err := ij.decodeConfigValue(raw, fieldType); err == nil {
+			converted = append(converted, value)
+		}
+	}
+
+	if len(converted) == 0 {
+		typeNames := make([]string, len(fieldTypes))
+
+		for i, fieldType := range fieldTypes {
+			typeNames[i] = fieldType.String()
+		}
+
+		return nil, fmt.Errorf(
+			"unable to convert: %s to the type of any field with this name: %s", string(raw), strings.Join(typeNames, ", "),
+		)
+	}
+
+	if len(fieldTypes) == 1 {
+		return converted[0].Interface(), nil
+	}
+
+	var value interface{}
+
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("unable to decode: %s, error: %s", string(raw), err.Error())
+	}
+
+	return value, nil
+}
+
+/* The distinct types of the exported registry struct fields with a name, sorted by name so errors are deterministic. */
+func (ij *injector) findGlobalFieldTypes(fieldName string) []reflect.Type {
+	var fieldTypes []reflect.Type
+
+	found := make(map[reflect.Type]bool)
+
+	for _, structType := range ij.tr.FindAllStructTypes() {
+		theType := reflect.TypeOf(structType)
+
+		if theType.Kind() != reflect.Struct {
+			continue
+		}
+
+		field, hasField := theType.FieldByName(fieldName)
+
+		if !hasField || field.PkgPath != "" || found[field.Type] {
+			continue
+		}
+
+		found[field.Type] = true
+		fieldTypes = append(fieldTypes, field.Type)
+	}
+
+	sort.Slice(fieldTypes, func(i, j int) bool { return fieldTypes[i].String() < fieldTypes[j].String() })
+
+	return fieldTypes
+}
+
+/* Converts a JSON value to the given type, using the converter for values such as "5s" for time.Duration. */
+func (ij *injector) decodeConfigValue(raw json.RawMessage, theType reflect.Type) (reflect.Value, error) {
+	ptr := reflect.New(theType)
+
+	if err := json.Unmarshal(raw, ptr.Interface()); err == nil {
+		return ptr.Elem(), nil
+	}
+
+	var value interface{}
+
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return reflect.Value{}, fmt.Errorf("unable to convert: %s to type: %s", string(raw), theType)
+	}
+
+	return ij.converter.Convert(value, theType)
+}
+
+/* Returns the keys of a map keyed by string in order, so problems are reported deterministically. */
+func sortedKeys(theMap interface{}) []string {
+	keys := reflect.ValueOf(theMap).MapKeys()
+	sorted := make([]string, len(keys))
+
+	for i, key := range keys {
+		sorted[i] = key.String()
+	}
+
+	sort.Strings(sorted)
+
+	return sorted
+}
@@ -0,0 +1,773 @@
+package Goij
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/j7mbo/goij/src/TypeRegistry"
+	"go/format"
+	"go/token"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/* ConstructorOptions is what GenerateConstructors() writes, and where it is written to. */
+type ConstructorOptions struct {
+	/* The package of the generated file, "main" if empty. */
+	Package string
+
+	/* The import path of the package of the generated file, so that it's own types are not imported. */
+	ImportPath string
+
+	/* The struct or interface names to write a constructor for, as they would be given to Make(). */
+	Roots []string
+
+	/* The start of the name of each constructor, followed by the short name of it's root. "Construct" if empty. */
+	Prefix string
+}
+
+/* Writes constructors from the decisions of Explain(), and the imports they need. */
+type constructorWriter struct {
+	ij      *injector
+	options ConstructorOptions
+
+	/* Import aliases by package path, and package paths by alias so that each alias is unique. */
+	imports map[string]string
+	aliases map[string]string
+
+	/* The roots by the name of their constructor, so that two roots can't have the same constructor. */
+	constructors map[string]string
+
+	functions bytes.Buffer
+
+	/* The constructor being written. */
+	body *constructorBody
+}
+
+type constructorBody struct {
+	statements []string
+
+	/* Shared objects are parameters of the constructor, by their type. */
+	params    map[reflect.Type]string
+	paramList []string
+
+	/* The names of the parameters and variables, which can be addressed. */
+	variables map[string]bool
+	lastVar   int
+
+	/* The cleanup functions returned so far, and the results returned early when a delegate or factory fails. */
+	cleanups     []string
+	errorReturns string
+}
+
+/*
+GenerateConstructors writes Go source with a constructor for each root, that makes it the same way as Make() does with
+the injector, which must be one returned by NewInjector().
+
+The constructors are made from the same decisions as Explain(): they initialise structs, call delegates and factories
+and set fields from definitions, so that wiring mistakes like a type mismatch break the build. Shared objects become
+parameters of the constructors. Delegates must be exported functions rather than closures, and values only known when
+the program runs, like environment variables, can't be generated.
+*/
+func GenerateConstructors(from Injector, writer io.Writer, options ConstructorOptions) error {
+	ij, isInjector := from.(*injector)
+
+	if !isInjector {
+		return fmt.Errorf("Constructors can only be generated with an injector from NewInjector(), got: %T", from)
+	}
+
+	if options.Package == "" {
+		options.Package = "main"
+	}
+
+	if options.Prefix == "" {
+		options.Prefix = "Construct"
+	}
+
+	w := &constructorWriter{
+		ij:           ij,
+		options:      options,
+		imports:      make(map[string]string),
+		aliases:      make(map[string]string),
+		constructors: make(map[string]string),
+	}
+
+	for _, root := range options.Roots {
+		resolution, err := ij.Explain(root)
+
+		if err == nil {
+			err = w.constructor(root, resolution)
+		}
+
+		if err != nil {
+			return fmt.Errorf("Unable to generate a constructor for: '%s', error: %s", root, err.Error())
+		}
+	}
+
+	source, err := format.Source(w.file())
+
+	if err != nil {
+		return fmt.Errorf("Unable to format the generated constructors, error: %s", err.Error())
+	}
+
+	_, err = writer.Write(source)
+
+	return err
+}
+
+/* Writes the constructor for a root, returning what Make() would return for it. */
+func (w *constructorWriter) constructor(root string, resolution *Resolution) error {
+	name := w.options.Prefix + exportedName(resolution.Path)
+
+	if other, found := w.constructors[name]; found {
+		return fmt.Errorf("the constructor: %s is already generated for: '%s', use a different prefix", name, other)
+	}
+
+	w.constructors[name] = root
+	w.body = &constructorBody{params: make(map[reflect.Type]string), variables: make(map[string]bool)}
+
+	/* Make() returns a pointer to the struct, or whatever the delegate or factory returned, as is. */
+	returnType := reflect.PtrTo(derefType(resolution.Provided))
+
+	switch {
+	case resolution.Requested.Kind() == reflect.Interface:
+		returnType = resolution.Requested
+	case resolution.Source == SourceDelegate || resolution.Source == SourceFactory:
+		returnType = resolution.Provided
+	}
+
+	returnTypeName, err := w.typeName(returnType)
+
+	if err != nil {
+		return err
+	}
+
+	/* The constructor returns a cleanup function and an error too, if any delegate or factory does. */
+	hasCleanup, hasError := returnsCleanupOrError(resolution)
+
+	results := []string{returnTypeName}
+
+	if hasCleanup {
+		results = append(results, "func()")
+	}
+
+	if hasError {
+		zeroValue, err := w.zeroExpression(returnType)
+
+		if err != nil {
+			return err
+		}
+
+		w.body.errorReturns = zeroValue
+
+		if hasCleanup {
+			w.body.errorReturns += ", nil"
+		}
+
+		results = append(results, "error")
+	}
+
+	value, err := w.value(resolution, returnType)
+
+	if err != nil {
+		return err
+	}
+
+	returns := []string{value}
+
+	switch {
+	case !hasCleanup:
+	case len(w.body.cleanups) == 1:
+		returns = append(returns, w.body.cleanups[0])
+	default:
+		returns = append(returns, "func() {\n"+w.cleanupCalls()+"\t}")
+	}
+
+	if hasError {
+		returns = append(returns, "nil")
+	}
+
+	signature := strings.Join(results, ", ")
+
+	if len(results) > 1 {
+		signature = "(" + signature + ")"
+	}
+
+	fmt.Fprintf(&w.functions, "\n/* %s makes %q the same way as Make(). */\n", name, root)
+	fmt.Fprintf(&w.functions, "func %s(%s) %s {\n", name, strings.Join(w.body.paramList, ", "), signature)
+
+	for _, statement := range w.body.statements {
+		fmt.Fprintf(&w.functions, "\t%s\n", statement)
+	}
+
+	fmt.Fprintf(&w.functions, "\n\treturn %s\n}\n", strings.Join(returns, ", "))
+
+	return nil
+}
+
+/* Whether any delegate or factory for a resolution returns a cleanup function, and whether any returns an error. */
+func returnsCleanupOrError(resolution *Resolution) (hasCleanup bool, hasError bool) {
+	resolution.walkDepth(0, func(node *Resolution, depth int) {
+		if node.delegate == nil || (node.Source != SourceDelegate && node.Source != SourceFactory) {
+			return
+		}
+
+		functionType := getElem(node.delegate.function).Type()
+		numOut := functionType.NumOut()
+
+		if numOut > 1 && functionType.Out(numOut-1) == errorType {
+			hasError = true
+			numOut--
+		}
+
+		if numOut > 1 && functionType.Out(numOut-1) == cleanupType {
+			hasCleanup = true
+		}
+	})
+
+	return hasCleanup, hasError
+}
+
+/* Calls the cleanup functions returned so far, in reverse order like Shutdown(). */
+func (w *constructorWriter) cleanupCalls() string {
+	var calls strings.Builder
+
+	for i := len(w.body.cleanups) - 1; i >= 0; i-- {
+		fmt.Fprintf(&calls, "\t\t%s()\n", w.body.cleanups[i])
+	}
+
+	return calls.String()
+}
+
+/* The expression for the value of a resolution, as the type it is injected into. */
+func (w *constructorWriter) value(node *Resolution, target reflect.Type) (string, error) {
+	switch node.Source {
+	case SourceDefinition, SourceNamedTypeDefinition, SourceGlobalDefinition, SourceTypeDefinition, SourceArgDefinition:
+		value, err := w.ij.converter.Convert(node.value, node.Requested)
+
+		if err != nil {
+			return "", fmt.Errorf("%s: %s", node.Path, err.Error())
+		}
+
+		literal, err := w.literal(value)
+
+		if err != nil {
+			return "", fmt.Errorf("%s: the %s %s", node.Path, node.Source, err.Error())
+		}
+
+		return literal, nil
+	case SourceZeroValue:
+		return w.zeroValue(node.Requested)
+	case SourceShared:
+		return w.shared(node, target)
+	case SourceDelegate, SourceFactory:
+		return w.call(node, target)
+	case SourceRegistry, SourceNew:
+		return w.initialise(node, target)
+	}
+
+	return "", fmt.Errorf(
+		"%s: a value from the %s is only known when the program runs, define it instead", node.Path, node.Source,
+	)
+}
+
+/* Initialises a struct and sets each of it's fields that isn't left as the zero value. */
+func (w *constructorWriter) initialise(node *Resolution, target reflect.Type) (string, error) {
+	structType := derefType(node.Provided)
+
+	structTypeName, err := w.typeName(structType)
+
+	if err != nil {
+		return "", err
+	}
+
+	fields := make([]string, 0, len(node.Dependencies))
+
+	for _, field := range node.Dependencies {
+		if field.Source == SourceZeroValue {
+			continue
+		}
+
+		value, err := w.value(field, field.Requested)
+
+		if err != nil {
+			return "", err
+		}
+
+		fields = append(fields, field.Name, value)
+	}
+
+	variable := w.variable(fmt.Sprintf("&%s{}", structTypeName))
+
+	for i := 0; i < len(fields); i += 2 {
+		w.add("%s.%s = %s", variable, fields[i], fields[i+1])
+	}
+
+	return w.convert(variable, reflect.PtrTo(structType), target)
+}
+
+/* Calls a delegate or factory with each of it's arguments. */
+func (w *constructorWriter) call(node *Resolution, target reflect.Type) (string, error) {
+	function, err := w.functionName(node.Function)
+
+	if err != nil {
+		return "", fmt.Errorf("%s: %s", node.Path, err.Error())
+	}
+
+	functionType := getElem(node.delegate.function).Type()
+
+	args := make([]string, 0, len(node.Dependencies))
+
+	for i, arg := range node.Dependencies {
+		value, err := w.value(arg, functionType.In(i))
+
+		if err != nil {
+			return "", err
+		}
+
+		args = append(args, value)
+	}
+
+	if functionType.IsVariadic() && len(args) > 0 {
+		args[len(args)-1] += "..."
+	}
+
+	/* Only the first result is injected, like with Make(), then the cleanup function and error are kept. */
+	results := []string{w.nextVariable()}
+	cleanup, returnsError := "", false
+
+	for i := 1; i < functionType.NumOut(); i++ {
+		switch {
+		case i == functionType.NumOut()-1 && functionType.Out(i) == errorType:
+			results = append(results, "err")
+			returnsError = true
+		case i == 1 && functionType.Out(i) == cleanupType:
+			cleanup = fmt.Sprintf("cleanup%d", w.body.lastVar)
+			results = append(results, cleanup)
+		default:
+			results = append(results, "_")
+		}
+	}
+
+	w.add("%s := %s(%s)", strings.Join(results, ", "), function, strings.Join(args, ", "))
+
+	/* The cleanup functions returned before are called, as the constructor fails and doesn't return them. */
+	if returnsError {
+		w.add("\n\tif err != nil {\n%s\t\treturn %s, err\n\t}\n", w.cleanupCalls(), w.body.errorReturns)
+	}
+
+	if cleanup != "" {
+		w.body.cleanups = append(w.body.cleanups, cleanup)
+	}
+
+	return w.convert(results[0], functionType.Out(0), target)
+}
+
+/* A copy of a shared object, which is given to the constructor as a parameter. */
+func (w *constructorWriter) shared(node *Resolution, target reflect.Type) (string, error) {
+	structType := derefType(node.Provided)
+
+	param, found := w.body.params[structType]
+
+	if !found {
+		structTypeName, err := w.typeName(structType)
+
+		if err != nil {
+			return "", err
+		}
+
+		param = "shared" + exportedName(structType.Name())
+
+		for i := 2; w.body.variables[param]; i++ {
+			param = fmt.Sprintf("shared%s%d", exportedName(structType.Name()), i)
+		}
+
+		w.body.params[structType] = param
+		w.body.variables[param] = true
+		w.body.paramList = append(w.body.paramList, fmt.Sprintf("%s *%s", param, structTypeName))
+	}
+
+	return w.convert(w.variable("*"+param), structType, target)
+}
+
+/* Scalar arguments without a definition are the zero value, and pointers to them point to a zero value. */
+func (w *constructorWriter) zeroValue(valueType reflect.Type) (string, error) {
+	if valueType.Kind() == reflect.Ptr {
+		elemTypeName, err := w.typeName(valueType.Elem())
+
+		return fmt.Sprintf("new(%s)", elemTypeName), err
+	}
+
+	typeName, err := w.typeName(valueType)
+
+	if err != nil {
+		return "", err
+	}
+
+	variable := w.nextVariable()
+
+	w.add("var %s %s", variable, typeName)
+
+	return variable, nil
+}
+
+/* The expression for the zero value of a type, returned when a delegate or factory fails. */
+func (w *constructorWriter) zeroExpression(valueType reflect.Type) (string, error) {
+	switch valueType.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return "nil", nil
+	}
+
+	typeName, err := w.typeName(valueType)
+
+	if valueType.Kind() == reflect.Struct || valueType.Kind() == reflect.Array {
+		return typeName + "{}", err
+	}
+
+	return fmt.Sprintf("*new(%s)", typeName), err
+}
+
+/*
+Converts an expression to the type it is injected into, the same way as Make(): dereferencing pointers for values,
+and taking the address of values for pointers. Values are injected into interfaces as a pointer to them.
+
+Anything else is left for the compiler to report, as the injector would fail to inject it too.
+*/
+func (w *constructorWriter) convert(expression string, from reflect.Type, to reflect.Type) (string, error) {
+	switch {
+	case from == to:
+		return expression, nil
+	case to.Kind() == reflect.Interface && from.Kind() == reflect.Struct:
+		return "&" + w.variable(expression), nil
+	case from.Kind() == reflect.Ptr && from.Elem() == to:
+		return "*" + expression, nil
+	case to.Kind() == reflect.Ptr && to.Elem() == from:
+		return "&" + w.variable(expression), nil
+	}
+
+	return expression, nil
+}
+
+/* Assigns an expression to a new variable, unless it is one already, so it can be addressed. */
+func (w *constructorWriter) variable(expression string) string {
+	if w.body.variables[expression] {
+		return expression
+	}
+
+	variable := w.nextVariable()
+
+	w.add("%s := %s", variable, expression)
+
+	return variable
+}
+
+func (w *constructorWriter) nextVariable() string {
+	w.body.lastVar++
+
+	variable := fmt.Sprintf("v%d", w.body.lastVar)
+	w.body.variables[variable] = true
+
+	return variable
+}
+
+func (w *constructorWriter) add(format string, args ...interface{}) {
+	w.body.statements = append(w.body.statements, fmt.Sprintf(format, args...))
+}
+
+/* The Go literal for a definition, which has already been converted to the type it is injected into. */
+func (w *constructorWriter) literal(value reflect.Value) (string, error) {
+	valueType := value.Type()
+
+	switch valueType.Kind() {
+	case reflect.Bool, reflect.String, reflect.Int:
+		literal := basicLiteral(value)
+
+		/* Untyped constants are only the right type without a conversion for the predeclared types. */
+		if valueType.PkgPath() == "" {
+			return literal, nil
+		}
+
+		return w.conversion(valueType, literal)
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Complex64, reflect.Complex128:
+		return w.conversion(valueType, basicLiteral(value))
+	case reflect.Float32, reflect.Float64:
+		if math.IsInf(value.Float(), 0) || math.IsNaN(value.Float()) {
+			return "", fmt.Errorf("%v can't be written as a Go constant", value.Interface())
+		}
+
+		return w.conversion(valueType, basicLiteral(value))
+	case reflect.Slice, reflect.Map:
+		if value.IsNil() {
+			return w.conversion(valueType, "nil")
+		}
+
+		return w.compositeLiteral(value)
+	case reflect.Array, reflect.Struct:
+		return w.compositeLiteral(value)
+	case reflect.Ptr:
+		if value.IsNil() {
+			return w.conversion(valueType, "nil")
+		}
+
+		literal, err := w.literal(value.Elem())
+
+		if err != nil || valueType.Elem().Kind() == reflect.Struct {
+			return "&" + literal, err
+		}
+
+		return "&" + w.variable(literal), nil
+	case reflect.Interface:
+		if value.IsNil() {
+			return "nil", nil
+		}
+
+		return w.literal(value.Elem())
+	}
+
+	return "", fmt.Errorf("of type: %s can't be written as Go code", valueType)
+}
+
+/* The elements of a slice, array or map, or the exported fields of a struct that aren't the zero value. */
+func (w *constructorWriter) compositeLiteral(value reflect.Value) (string, error) {
+	typeName, err := w.typeName(value.Type())
+
+	if err != nil {
+		return "", err
+	}
+
+	var elements []string
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			element, err := w.literal(value.Index(i))
+
+			if err != nil {
+				return "", err
+			}
+
+			elements = append(elements, element)
+		}
+	case reflect.Map:
+		for _, key := range value.MapKeys() {
+			keyLiteral, err := w.literal(key)
+
+			if err != nil {
+				return "", err
+			}
+
+			element, err := w.literal(value.MapIndex(key))
+
+			if err != nil {
+				return "", err
+			}
+
+			elements = append(elements, keyLiteral+": "+element)
+		}
+
+		/* Maps are iterated in a random order, but the generated code shouldn't change. */
+		sort.Strings(elements)
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			if value.Field(i).IsZero() {
+				continue
+			}
+
+			if value.Type().Field(i).PkgPath != "" {
+				return "", fmt.Errorf("of type: %s has private fields, so can't be written as Go code", value.Type())
+			}
+
+			element, err := w.literal(value.Field(i))
+
+			if err != nil {
+				return "", err
+			}
+
+			elements = append(elements, value.Type().Field(i).Name+": "+element)
+		}
+	}
+
+	return fmt.Sprintf("%s{%s}", typeName, strings.Join(elements, ", ")), nil
+}
+
+func (w *constructorWriter) conversion(valueType reflect.Type, literal string) (string, error) {
+	typeName, err := w.typeName(valueType)
+
+	if valueType.Kind() == reflect.Ptr {
+		typeName = "(" + typeName + ")"
+	}
+
+	return fmt.Sprintf("%s(%s)", typeName, literal), err
+}
+
+/* The literal for a bool, string or number, without it's type. */
+func basicLiteral(value reflect.Value) string {
+	switch value.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool())
+	case reflect.String:
+		return strconv.Quote(value.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(value.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'g', -1, value.Type().Bits())
+	}
+
+	return fmt.Sprint(value.Interface())
+}
+
+/* The name of a type in the generated code, importing it's package. */
+func (w *constructorWriter) typeName(valueType reflect.Type) (string, error) {
+	if valueType.Name() != "" {
+		if valueType.PkgPath() == "" {
+			return valueType.Name(), nil
+		}
+
+		if strings.Contains(valueType.Name(), "[") {
+			return "", fmt.Errorf("the generic type: %s can't be written as Go code", valueType)
+		}
+
+		if !token.IsExported(valueType.Name()) && valueType.PkgPath() != w.options.ImportPath {
+			return "", fmt.Errorf("the type: %s is private to it's package", valueType)
+		}
+
+		return w.qualify(valueType.PkgPath(), valueType.Name()), nil
+	}
+
+	var elemTypeName string
+	var err error
+
+	if kind := valueType.Kind(); kind == reflect.Ptr || kind == reflect.Slice || kind == reflect.Array ||
+		kind == reflect.Map || kind == reflect.Chan {
+		if elemTypeName, err = w.typeName(valueType.Elem()); err != nil {
+			return "", err
+		}
+	}
+
+	switch valueType.Kind() {
+	case reflect.Ptr:
+		return "*" + elemTypeName, nil
+	case reflect.Slice:
+		return "[]" + elemTypeName, nil
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", valueType.Len(), elemTypeName), nil
+	case reflect.Map:
+		keyTypeName, err := w.typeName(valueType.Key())
+
+		return fmt.Sprintf("map[%s]%s", keyTypeName, elemTypeName), err
+	case reflect.Chan:
+		return valueType.ChanDir().String() + " " + elemTypeName, nil
+	case reflect.Interface:
+		if valueType.NumMethod() == 0 {
+			return "interface{}", nil
+		}
+	case reflect.Struct:
+		if valueType.NumField() == 0 {
+			return "struct{}", nil
+		}
+	}
+
+	return "", fmt.Errorf("the type: %s can't be written as Go code", valueType)
+}
+
+/* The name of a delegate or factory in the generated code, which must be an exported function of it's package. */
+func (w *constructorWriter) functionName(fullName string) (string, error) {
+	lastSlash := strings.LastIndex(fullName, "/")
+	dot := strings.Index(fullName[lastSlash+1:], ".")
+
+	if dot >= 0 {
+		pkgPath, name := fullName[:lastSlash+1+dot], fullName[lastSlash+2+dot:]
+
+		if token.IsIdentifier(name) && pkgPath != "main" && (token.IsExported(name) || pkgPath == w.options.ImportPath) {
+			return w.qualify(pkgPath, name), nil
+		}
+	}
+
+	return "", fmt.Errorf(
+		"the function: %s can't be called from generated code, as it is a closure, method or private; "+
+			"delegate an exported function instead", fullName,
+	)
+}
+
+/* Qualifies a name with the alias of it's package, unless it's in the package of the generated file. */
+func (w *constructorWriter) qualify(pkgPath string, name string) string {
+	if pkgPath == w.options.ImportPath {
+		return name
+	}
+
+	if alias, found := w.imports[pkgPath]; found {
+		return alias + "." + name
+	}
+
+	alias := TypeRegistry.ImportAlias(pkgPath, w.isTaken)
+
+	w.imports[pkgPath] = alias
+	w.aliases[alias] = pkgPath
+
+	return alias + "." + name
+}
+
+/* Whether an alias is taken by another package, or would hide a variable, parameter or constructor. */
+func (w *constructorWriter) isTaken(alias string) bool {
+	if w.aliases[alias] != "" || alias == "err" || strings.HasPrefix(alias, "shared") {
+		return true
+	}
+
+	if strings.HasPrefix(alias, w.options.Prefix) {
+		return true
+	}
+
+	for _, prefix := range []string{"v", "cleanup"} {
+		if _, err := strconv.Atoi(strings.TrimPrefix(alias, prefix)); err == nil && strings.HasPrefix(alias, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+/* The generated file, with the imports needed by the constructors. */
+func (w *constructorWriter) file() []byte {
+	var file bytes.Buffer
+
+	file.WriteString("// Code generated by Goij from the type registry. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&file, "package %s\n", w.options.Package)
+
+	if len(w.imports) > 0 {
+		pkgPaths := make([]string, 0, len(w.imports))
+
+		for pkgPath := range w.imports {
+			pkgPaths = append(pkgPaths, pkgPath)
+		}
+
+		sort.Strings(pkgPaths)
+
+		file.WriteString("\nimport (\n")
+
+		for _, pkgPath := range pkgPaths {
+			fmt.Fprintf(&file, "\t%s %q\n", w.imports[pkgPath], pkgPath)
+		}
+
+		file.WriteString(")\n")
+	}
+
+	file.Write(w.functions.Bytes())
+
+	return file.Bytes()
+}
+
+/* The name with it's first letter upper case, so that it is exported when it's at the end of a function name. */
+func exportedName(name string) string {
+	if name == "" {
+		return name
+	}
+
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -0,0 +1,78 @@
+package Goij
+
+import (
+	"reflect"
+)
+
+/* Define a value for every field and argument of the sample's type. */
+func (ij *injector) DefineType(sample interface{}, value interface{}) {
+	ij.typeDefinitions[ij.definitionType(sample)] = value
+
+	ij.invalidatePlans()
+}
+
+/* Define a value for every field and argument of the sample's type with the given name. */
+func (ij *injector) DefineNamedType(sample interface{}, paramName string, value interface{}) {
+	definitionType := ij.definitionType(sample)
+
+	if _, found := ij.namedTypeDefinitions[definitionType]; !found {
+		ij.namedTypeDefinitions[definitionType] = make(map[string]interface{})
+	}
+
+	ij.namedTypeDefinitions[definitionType][paramName] = value
+
+	ij.invalidatePlans()
+}
+
+/* Register a conversion of definitions to the sample's type. */
+func (ij *injector) RegisterConverter(sample interface{}, conversion func(value interface{}) (interface{}, error)) {
+	ij.converter.Register(ij.definitionType(sample), conversion)
+
+	ij.invalidatePlans()
+}
+
+/* The type to define for the sample; a nil pointer to an interface means the interface itself. */
+func (ij *injector) definitionType(sample interface{}) reflect.Type {
+	if sample == nil {
+		ij.panic("A sample value is required to define a type, to define an interface use a nil pointer to it")
+	}
+
+	sampleType := reflect.TypeOf(sample)
+
+	if sampleType.Kind() == reflect.Ptr && sampleType.Elem().Kind() == reflect.Interface {
+		return sampleType.Elem()
+	}
+
+	return sampleType
+}
+
+/*
+Finds a definition for the given type, and for the name if one is provided. A definition for a type is also used for
+pointers to that type.
+*/
+func (ij *injector) findTypeDefinition(theType reflect.Type, name string) (interface{}, bool) {
+	for _, candidate := range []reflect.Type{theType, derefType(theType)} {
+		if name == "" {
+			if definition, found := ij.typeDefinitions[candidate]; found {
+				return definition, true
+			}
+
+			continue
+		}
+
+		if definition, found := ij.namedTypeDefinitions[candidate][name]; found {
+			return definition, true
+		}
+	}
+
+	return nil, false
+}
+
+/* Given a pointer type, returns the type it points to, otherwise the type itself. */
+func derefType(theType reflect.Type) reflect.Type {
+	if theType.Kind() == reflect.Ptr {
+		return theType.Elem()
+	}
+
+	return theType
+}
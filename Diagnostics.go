@@ -0,0 +1,118 @@
+package Goij
+
+import (
+	"errors"
+	"strings"
+)
+
+/* Recovered in diagnostics mode without being recorded, as the issues that caused it have been recorded already. */
+var errIssuesRecorded = errors.New("issues have been recorded for the arguments")
+
+/* The issues recorded by a Make() in diagnostics mode, and the path of the field or argument being resolved. */
+type diagnosis struct {
+	root   string
+	path   []string
+	issues []*ResolutionIssue
+}
+
+func (ij *injector) Diagnostics(enabled bool) {
+	ij.diagnostics = enabled
+}
+
+/* Makes a type in diagnostics mode, then panics with a *ResolutionError if any issues were recorded. */
+func (ij *injector) makeWithDiagnostics(name string) (obj interface{}) {
+	/* Delegates can Make() too, so keep the diagnosis of any Make() in progress. */
+	previous := ij.diagnosis
+	current := &diagnosis{root: name, path: []string{name[strings.LastIndex(name, ".")+1:]}}
+
+	ij.diagnosis = current
+
+	ij.diagnose("", func() {
+		obj = ij.makeType(name)
+	})
+
+	ij.diagnosis = previous
+
+	if len(current.issues) > 0 {
+		ij.panicWithError(&ResolutionError{Issues: current.issues})
+	}
+
+	return obj
+}
+
+/*
+Resolves the field or argument with the given name. In diagnostics mode any failure is recorded with the path of the
+field or argument and recovered, so that the remaining fields and arguments are still resolved.
+*/
+func (ij *injector) diagnose(name string, resolve func()) {
+	current := ij.diagnosis
+
+	if current == nil {
+		/* The path is only kept for the errors returned by delegates and factories, and is reset by makeType(). */
+		if name != "" {
+			ij.resolutionPath = append(ij.resolutionPath, name)
+		}
+
+		resolve()
+
+		if name != "" {
+			ij.resolutionPath = ij.resolutionPath[:len(ij.resolutionPath)-1]
+		}
+
+		return
+	}
+
+	if name != "" {
+		current.path = append(current.path, name)
+
+		defer func() {
+			current.path = current.path[:len(current.path)-1]
+		}()
+	}
+
+	defer func() {
+		problem := recover()
+
+		switch typedProblem := problem.(type) {
+		case nil:
+		case *ResolutionError:
+			/* A Make() in a delegate has recorded it's own issues. */
+			current.issues = append(current.issues, typedProblem.Issues...)
+		default:
+			if problem != errIssuesRecorded {
+				current.issues = append(current.issues, newIssue(current.root, strings.Join(current.path, "."), problem))
+			}
+		}
+	}()
+
+	resolve()
+}
+
+/* The number of issues recorded by the Make() in progress in diagnostics mode. */
+func (ij *injector) diagnosedIssues() int {
+	if ij.diagnosis == nil {
+		return 0
+	}
+
+	return len(ij.diagnosis.issues)
+}
+
+/*
+Fails to resolve; panics with the message, or with the failure in diagnostics mode so that it's kind is recorded. Errors
+returned by delegates and factories are kept, with the path they were returned at, so they can be inspected on recovery.
+*/
+func (ij *injector) fail(err error) {
+	ij.emit(Event{Kind: EventError, Err: err})
+
+	if failure, isFailure := err.(*resolutionFailure); isFailure && failure.err != nil && ij.diagnosis == nil {
+		ij.panicWithError(newIssue(ij.resolutionRoot, strings.Join(ij.resolutionPath, "."), failure))
+	}
+
+	if ij.diagnosis == nil {
+		ij.panic(err.Error())
+	}
+
+	ij.elog(err.Error())
+
+	panic(err)
+}
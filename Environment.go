@@ -0,0 +1,180 @@
+package Goij
+
+import (
+	"fmt"
+	"github.com/j7mbo/goij/src/Logger"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+/* The struct tag names used for environment variable injection. */
+const (
+	envTag      = "env"
+	envDefault  = "default="
+	envRequired = "required"
+)
+
+func (ij *injector) DefineFromEnv(prefix string) error {
+	prefix = strings.ToUpper(strings.TrimSuffix(prefix, "_"))
+
+	if prefix != "" {
+		prefix += "_"
+	}
+
+	var errs []string
+
+	structTypes := ij.tr.FindAllStructTypes()
+	structNames := make([]string, 0, len(structTypes))
+
+	for structName := range structTypes {
+		structNames = append(structNames, structName)
+	}
+
+	/* Sorted so that the errors are always in the same order. */
+	sort.Strings(structNames)
+
+	for _, structName := range structNames {
+		theType := reflect.TypeOf(structTypes[structName])
+
+		if theType.Kind() != reflect.Struct {
+			continue
+		}
+
+		shortName := strings.ToUpper(theType.Name())
+
+		for i := 0; i < theType.NumField(); i++ {
+			field := theType.Field(i)
+
+			if field.PkgPath != "" {
+				continue
+			}
+
+			envName := prefix + shortName + "_" + strings.ToUpper(field.Name)
+
+			raw, found := os.LookupEnv(envName)
+
+			if !found {
+				continue
+			}
+
+			value, err := ij.converter.Convert(raw, field.Type)
+
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("'%s' for field: '%s': %s", envName, field.Name, err.Error()))
+
+				continue
+			}
+
+			ij.debug(
+				"Defining field from environment variable",
+				Logger.FieldName(field.Name),
+				Logger.Any("object", structName),
+				Logger.Any("variable", envName),
+			)
+
+			ij.Define(structName, field.Name, value.Interface())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("Unable to define from environment variables: %s", strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+/*
+Finds the value for a field tagged with `env:"NAME"`, converted to the type of the field.
+
+The `env:"NAME,default=value"` option is used when the variable is not set, and `env:"NAME,required"` panics when
+neither exist.
+*/
+func (ij *injector) findEnvTagValue(field reflect.StructField, parentObj interface{}) (reflect.Value, bool) {
+	envName, raw, found, missing := lookupEnvTag(field)
+
+	if missing {
+		ij.fail(
+			newFailure(
+				IssueMissingEnvironment,
+				"Environment variable: '%s' is required for field: '%s' on object: %T but is not set",
+				envName, field.Name, parentObj,
+			),
+		)
+	}
+
+	if !found {
+		return reflect.Value{}, false
+	}
+
+	value, err := ij.converter.Convert(raw, field.Type)
+
+	if err != nil {
+		ij.fail(
+			newFailure(
+				IssueDefinitionMismatch,
+				"Environment variable: '%s' could not be used for field: '%s' on object: %T, error: %s",
+				envName, field.Name, parentObj, err.Error(),
+			),
+		)
+	}
+
+	ij.debug(
+		"Injecting environment variable",
+		Logger.FieldName(field.Name),
+		Logger.Type(field.Type),
+		Logger.Source(SourceEnvironment),
+		Logger.Any("variable", envName),
+	)
+
+	ij.emit(Event{Kind: EventDefinitionApplied, Field: field.Name, Type: field.Type, Source: SourceEnvironment})
+
+	return value, true
+}
+
+/*
+Looks up the raw value for a field tagged with `env:"NAME"`, or it's `default=value` option.
+
+The default is the rest of the tag after "default=", so it must be the last option and can contain commas for slices,
+like `env:"TAGS,default=a,b"`. Reports whether the variable is required and neither exist, without panicking so that it
+can also be verified.
+*/
+func lookupEnvTag(field reflect.StructField) (envName string, raw string, found bool, missing bool) {
+	tag, hasTag := field.Tag.Lookup(envTag)
+
+	if !hasTag {
+		return "", "", false, false
+	}
+
+	options := strings.Split(tag, ",")
+	envName = strings.TrimSpace(options[0])
+
+	var defaultValue string
+	var hasDefault, required bool
+
+	for i, option := range options[1:] {
+		option = strings.TrimSpace(option)
+
+		if strings.HasPrefix(option, envDefault) {
+			defaultValue = strings.TrimPrefix(strings.TrimSpace(strings.Join(options[i+1:], ",")), envDefault)
+			hasDefault = true
+
+			break
+		}
+
+		if option == envRequired {
+			required = true
+		}
+	}
+
+	if raw, found = os.LookupEnv(envName); found {
+		return envName, raw, true, false
+	}
+
+	if hasDefault {
+		return envName, defaultValue, true, false
+	}
+
+	return envName, "", false, required
+}
@@ -0,0 +1,255 @@
+package Goij
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+/* NodeKind is the kind of a node in the dependency graph. */
+type NodeKind string
+
+const (
+	NodeStruct     NodeKind = "struct"
+	NodeInterface  NodeKind = "interface"
+	NodeDelegate   NodeKind = "delegate"
+	NodeFactory    NodeKind = "factory"
+	NodeShared     NodeKind = "shared"
+	NodeDefinition NodeKind = "definition"
+)
+
+/* EdgeKind is the kind of an edge in the dependency graph. */
+type EdgeKind string
+
+const (
+	/* From a struct to the dependency injected into one of it's fields. */
+	EdgeField EdgeKind = "field"
+
+	/* From a delegate or factory to the dependency injected into one of it's arguments. */
+	EdgeArgument EdgeKind = "argument"
+
+	/* From an interface to the struct, delegate or factory that provides it. */
+	EdgeBinding EdgeKind = "binding"
+)
+
+/* GraphNode is a type, delegate, factory, shared object or definition in the dependency graph. */
+type GraphNode struct {
+	ID    string   `json:"id"`
+	Kind  NodeKind `json:"kind"`
+	Label string   `json:"label"`
+}
+
+/* GraphEdge is a dependency of one node on another, labelled with the field or argument name or how it was bound. */
+type GraphEdge struct {
+	From  string   `json:"from"`
+	To    string   `json:"to"`
+	Kind  EdgeKind `json:"kind"`
+	Label string   `json:"label"`
+}
+
+/* Graph is the dependency graph the injector resolves for one or more root types, returned from Graph(). */
+type Graph struct {
+	Nodes []*GraphNode `json:"nodes"`
+	Edges []*GraphEdge `json:"edges"`
+
+	/* Node IDs by the type, function or path they are for, so each is only added once. */
+	nodeIDs map[string]string
+
+	/* Edges that have been added already. */
+	edges map[GraphEdge]bool
+}
+
+func (ij *injector) Graph(roots ...string) *Graph {
+	if len(roots) == 0 {
+		roots = sortedKeys(ij.tr.FindAllStructTypes())
+	}
+
+	graph := &Graph{
+		Nodes:   []*GraphNode{},
+		Edges:   []*GraphEdge{},
+		nodeIDs: make(map[string]string),
+		edges:   make(map[GraphEdge]bool),
+	}
+
+	for _, root := range roots {
+		graph.add(ij.analyse(root))
+	}
+
+	return graph
+}
+
+/*
+Adds the node providing a resolution and the nodes for each of it's dependencies. Returns the node that depends on it
+should point to, which is the interface when one was requested, and false if nothing could be resolved.
+*/
+func (g *Graph) add(r *Resolution) (string, bool) {
+	provider, found := g.addProvider(r)
+
+	if !found {
+		return "", false
+	}
+
+	edgeKind := EdgeField
+
+	if r.delegate != nil {
+		edgeKind = EdgeArgument
+	}
+
+	for _, dependency := range r.Dependencies {
+		if target, found := g.add(dependency); found {
+			g.addEdge(provider, target, edgeKind, dependency.Name)
+		}
+	}
+
+	if r.Requested == nil || r.Requested.Kind() != reflect.Interface || !isProvidedByType(r.Source) {
+		return provider, true
+	}
+
+	interfaceID := g.addNode("interface "+r.Requested.String(), NodeInterface, r.Requested.String())
+
+	binding := string(r.Binding)
+
+	if binding == "" {
+		binding = string(r.Source)
+	}
+
+	g.addEdge(interfaceID, provider, EdgeBinding, binding)
+
+	return interfaceID, true
+}
+
+/* Adds the struct, delegate, factory, shared object or definition node that provides the value for a resolution. */
+func (g *Graph) addProvider(r *Resolution) (string, bool) {
+	switch r.Source {
+	case SourceRegistry, SourceNew:
+		return g.addNode("struct "+r.Provided.String(), NodeStruct, r.Provided.String()), true
+	case SourceShared:
+		return g.addNode("shared "+r.Provided.String(), NodeShared, r.Provided.String()), true
+	case SourceDelegate, SourceFactory:
+		/* Named like "pkg.NewServer", without the package path. */
+		label := r.Function[strings.LastIndex(r.Function, "/")+1:]
+		kind := NodeDelegate
+
+		if r.delegate.isFactory {
+			kind = NodeFactory
+		}
+
+		return g.addNode(fmt.Sprintf("%s %s %s", kind, r.delegate.name, r.Function), kind, label), true
+	case SourceZeroValue, "":
+		return "", false
+	}
+
+	/* Definitions are for a single field or argument, so are not shared between dependants. */
+	return g.addNode("definition "+r.Path, NodeDefinition, fmt.Sprintf("%s (%s)", r.Source, r.Provided)), true
+}
+
+/* Whether a source provides a type that is bound to an interface, rather than a value. */
+func isProvidedByType(source Source) bool {
+	switch source {
+	case SourceRegistry, SourceNew, SourceShared, SourceDelegate, SourceFactory:
+		return true
+	}
+
+	return false
+}
+
+func (g *Graph) addNode(key string, kind NodeKind, label string) string {
+	if id, found := g.nodeIDs[key]; found {
+		return id
+	}
+
+	id := fmt.Sprintf("n%d", len(g.Nodes))
+
+	g.nodeIDs[key] = id
+	g.Nodes = append(g.Nodes, &GraphNode{ID: id, Kind: kind, Label: label})
+
+	return id
+}
+
+func (g *Graph) addEdge(from string, to string, kind EdgeKind, label string) {
+	edge := GraphEdge{From: from, To: to, Kind: kind, Label: label}
+
+	if g.edges[edge] {
+		return
+	}
+
+	g.edges[edge] = true
+	g.Edges = append(g.Edges, &edge)
+}
+
+/* DOT renders the graph in the Graphviz DOT language. */
+func (g *Graph) DOT() string {
+	shapes := map[NodeKind]string{
+		NodeStruct:     "box",
+		NodeInterface:  "ellipse",
+		NodeDelegate:   "cds",
+		NodeFactory:    "cds",
+		NodeShared:     "box3d",
+		NodeDefinition: "note",
+	}
+
+	lines := []string{"digraph Goij {"}
+
+	for _, node := range g.Nodes {
+		lines = append(
+			lines,
+			fmt.Sprintf("\t%s [label=%q shape=%s];", node.ID, string(node.Kind)+"\n"+node.Label, shapes[node.Kind]),
+		)
+	}
+
+	for _, edge := range g.Edges {
+		style := "solid"
+
+		if edge.Kind == EdgeBinding {
+			style = "dashed"
+		}
+
+		lines = append(lines, fmt.Sprintf("\t%s -> %s [label=%q style=%s];", edge.From, edge.To, edge.Label, style))
+	}
+
+	return strings.Join(append(lines, "}"), "\n") + "\n"
+}
+
+/* Mermaid renders the graph as a Mermaid flowchart. */
+func (g *Graph) Mermaid() string {
+	shapes := map[NodeKind][2]string{
+		NodeStruct:     {"[", "]"},
+		NodeInterface:  {"([", "])"},
+		NodeDelegate:   {"[[", "]]"},
+		NodeFactory:    {"[[", "]]"},
+		NodeShared:     {"[(", ")]"},
+		NodeDefinition: {">", "]"},
+	}
+
+	lines := []string{"graph TD"}
+
+	for _, node := range g.Nodes {
+		shape := shapes[node.Kind]
+		label := mermaidText(string(node.Kind) + ": " + node.Label)
+
+		lines = append(lines, fmt.Sprintf("\t%s%s\"%s\"%s", node.ID, shape[0], label, shape[1]))
+	}
+
+	for _, edge := range g.Edges {
+		arrow := "-->"
+
+		if edge.Kind == EdgeBinding {
+			arrow = "-.->"
+		}
+
+		lines = append(lines, fmt.Sprintf("\t%s %s|\"%s\"| %s", edge.From, arrow, mermaidText(edge.Label), edge.To))
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+/* JSON renders the graph as a JSON document with the nodes and edges. */
+func (g *Graph) JSON() ([]byte, error) {
+	return json.MarshalIndent(g, "", "  ")
+}
+
+/* Escapes the characters in a label that Mermaid would otherwise treat as syntax. */
+func mermaidText(text string) string {
+	return strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(text)
+}
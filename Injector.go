@@ -4,10 +4,13 @@ package Goij
 import (
 	"fmt"
 	"github.com/j7mbo/goij/src/Cache"
+	"github.com/j7mbo/goij/src/Converter"
 	"github.com/j7mbo/goij/src/Logger"
 	"github.com/j7mbo/goij/src/TypeRegistry"
+	"io"
 	"reflect"
 	"strings"
+	"time"
 )
 
 /* Injector is the interface returned from calling NewInjector() and contains the methods for dependency initialisation. */
@@ -45,6 +48,9 @@ type Injector interface {
 
 	/*
 		Define allows injection definitions for specific objects.
+
+		The struct name can also be the name of a factory function, like "pkg.NewServer", to define the value of the
+		factory's scalar argument with the given name. This requires the argument names in the generated registry.
 	*/
 	Define(structName string, paramName string, value interface{})
 
@@ -55,12 +61,145 @@ type Injector interface {
 	*/
 	DefineGlobal(paramName string, value interface{})
 
+	/*
+		DefineArg defines the value to inject into the argument at the given position of a delegate or factory.
+
+		The name can be either the name of the function, like "pkg.NewServer", or the name of the type that the
+		delegate or factory returns. Positions start at 0. This is how scalar arguments are provided to delegates and
+		factories, as Go does not allow retrieving function argument names with reflection.
+	*/
+	DefineArg(factoryOrTypeName string, position int, value interface{})
+
+	/*
+		DefineType allows the definition of a value to be injected into every field and argument of a type.
+
+		The type is taken from the sample, like time.Duration(0). For interfaces, use a nil pointer like (*io.Writer)(nil).
+		The value must be assignable to the type, which is checked when it is injected.
+	*/
+	DefineType(sample interface{}, value interface{})
+
+	/*
+		DefineNamedType allows the definition of a value for fields and arguments of a type with a specific name.
+
+		This takes precedence over DefineGlobal(), which is matched on name only, and DefineType().
+	*/
+	DefineNamedType(sample interface{}, paramName string, value interface{})
+
+	/*
+		RegisterConverter registers a conversion of definitions to the type of the sample, like time.Duration(0).
+
+		Definitions are converted to the type of the field or argument they are injected into. Strings, numbers,
+		time.Duration, url.URL, slices, named types and types implementing encoding.TextUnmarshaler are converted
+		automatically, a registered conversion takes precedence over these.
+	*/
+	RegisterConverter(sample interface{}, conversion func(value interface{}) (interface{}, error))
+
 	/*
 		Invoke executes a function on the given object and returns all return values as an array.
+
+		Methods with pointer receivers can be invoked on values, variadic methods are supported and nil can be passed
+		for interface or pointer parameters. Any failure panics with an *InvocationError describing the problem.
 	*/
 	Invoke(object interface{}, methodName string, args ...interface{}) []interface{}
+
+	/*
+		Call executes a function or closure with all of it's parameters provisioned by the injector.
+
+		Overrides are used first, in order, for the first parameter each one is assignable to. The remaining parameters
+		are resolved the same way as the arguments of a delegate. If the last return value of the function is an error,
+		it is returned as the error and is not included in the returned values.
+	*/
+	Call(function interface{}, overrides ...interface{}) ([]interface{}, error)
+
+	/*
+		DefineFromEnv defines scalar parameters for the structs in the type registry from environment variables.
+
+		A variable named PREFIX_STRUCTNAME_FIELDNAME, like APP_INDEXCONTROLLER_PORT, is converted to the type of the
+		field and defined as with Define(). Struct and field names are matched case-insensitively on their short names.
+	*/
+	DefineFromEnv(prefix string) error
+
+	/*
+		LoadConfig reads bindings, definitions, global definitions and shared objects from a JSON document.
+
+		The "bindings", "definitions" and "globals" sections are applied with Bind(), Define() and DefineGlobal(), and
+		each struct in the "shared" section is initialised with the given field values and Share()d. Values are
+		converted to the type of the field they are for. Nothing is applied if any problem is found; instead a
+		*ConfigurationError is returned listing every problem with it's JSON path.
+	*/
+	LoadConfig(reader io.Reader) error
+
+	/*
+		Verify checks that the given struct or interface names, or every struct in the registry, can be made.
+
+		The dependency graph is walked with the registry, bindings, definitions, shared objects and delegates the same
+		way as Make(), but nothing is initialised and no delegate or factory is called. Every problem found is returned
+		as a *ResolutionIssue with the path of fields and arguments leading to it, rather than stopping at the first.
+	*/
+	Verify(names ...string) []error
+
+	/*
+		Diagnostics enables or disables diagnostics mode for Make().
+
+		In diagnostics mode, a field or argument that can't be resolved is recorded as a *ResolutionIssue with it's path
+		and left as the zero value, and the rest of the fields are still resolved. Make() then panics with a
+		*ResolutionError listing every issue grouped by root type, instead of panicking at the first one.
+	*/
+	Diagnostics(enabled bool)
+
+	/*
+		Graph returns the dependency graph of the given struct or interface names, or of every struct in the registry.
+
+		The graph is made of the same decisions as Verify() and Make(), without initialising anything, and can be
+		rendered with DOT(), Mermaid() or JSON().
+	*/
+	Graph(roots ...string) *Graph
+
+	/*
+		Explain returns the tree of decisions Make() would take for a struct or interface name, without initialising
+		anything.
+
+		Each field and argument says where it's value comes from: a definition, global definition, shared object,
+		binding, user delegate, automatic factory or struct from the registry, and what else was considered for it.
+		The tree is returned even when there are problems, along with a *ResolutionError listing them.
+	*/
+	Explain(name string) (*Resolution, error)
+
+	/*
+		Plan returns the ordered steps Make() would take for a struct or interface name, without calling anything.
+
+		The steps are the structs that would be initialised, the delegates and factories that would be called with
+		where each of their arguments come from, where each field would be set from, and what would be cached. Plans
+		from injectors with different configurations can be compared with Compare(). Any problems are returned as a
+		*ResolutionError along with the plan.
+	*/
+	Plan(name string) (*Plan, error)
+
+	/*
+		OnEvent registers a function that is called with each event from the resolution pipeline, like the start and end
+		of a Make(), cache hits and misses, delegates and factories called, bindings and definitions applied and errors.
+
+		Each event has the ID of the event it happened within, and delegates, factories and the end of a Make() have the
+		time they took, for metrics and tracing.
+	*/
+	OnEvent(observer func(Event))
+
+	/*
+		Shutdown calls the cleanup functions returned by delegates and factories, in the reverse order of the objects
+		being made, so that objects are cleaned up before what they depend on.
+
+		Delegates and factories can return a cleanup function after the object, and optionally an error after that, like
+		"func NewDB(dsn string) (*DB, func(), error)". The cleanup function is not kept when the error is returned.
+	*/
+	Shutdown()
 }
 
+/* The reflected error interface type, used to detect functions returning an error. */
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+/* The type of the cleanup functions returned by delegates and factories, like "func() (*DB, func(), error)". */
+var cleanupType = reflect.TypeOf(func() {})
+
 type injector struct {
 	/* Registry of all application types. */
 	tr *TypeRegistry.TypeRegistry
@@ -72,7 +211,7 @@ type injector struct {
 	delegates Cache.DelegateCache
 
 	/* Optional if you want to know what wizardry is occurring. */
-	logger *Logger.Logger
+	logger Logger.Logger
 
 	/* Bindings from interface to concrete. */
 	bindings map[string]string
@@ -82,9 +221,46 @@ type injector struct {
 
 	/* Global scalar parameter definitions. */
 	globalDefinitions map[string]interface{}
+
+	/* Delegate and factory argument definitions by position. */
+	argDefinitions map[string]map[int]interface{}
+
+	/* Definitions by type, for every field or argument of that type. */
+	typeDefinitions map[reflect.Type]interface{}
+
+	/* Definitions by type, for fields or arguments of that type with a specific name. */
+	namedTypeDefinitions map[reflect.Type]map[string]interface{}
+
+	/* Converts definitions to the type of the field or argument they are injected into. */
+	converter Converter.Converter
+
+	/* Whether Make() records every issue and panics with all of them, rather than with the first. */
+	diagnostics bool
+
+	/* The issues recorded by the Make() in progress in diagnostics mode. */
+	diagnosis *diagnosis
+
+	/* Called with each event from the resolution pipeline. */
+	observers []func(Event)
+
+	/* The ID of the last event, so that each has a unique ID. */
+	lastEventID uint64
+
+	/* The resolve, delegate and factory events in progress, that other events are children of. */
+	eventSpans []*eventSpan
+
+	/* Decisions compiled for each type, until the configuration or registry changes. */
+	plans *typePlans
+
+	/* The name given to the Make() in progress, and the path of fields and arguments being resolved for it. */
+	resolutionRoot string
+	resolutionPath []string
+
+	/* Returned from delegates and factories, to be called by Shutdown(). */
+	cleanups []func()
 }
 
-func NewInjector(tr *TypeRegistry.TypeRegistry, logger *Logger.Logger) Injector {
+func NewInjector(tr *TypeRegistry.TypeRegistry, logger Logger.Logger) Injector {
 	return &injector{
 		tr:                tr,
 		logger:            logger,
@@ -92,12 +268,60 @@ func NewInjector(tr *TypeRegistry.TypeRegistry, logger *Logger.Logger) Injector
 		delegates:         Cache.NewDelegateCache(),
 		definitions:       make(map[string]map[string]interface{}),
 		globalDefinitions: make(map[string]interface{}),
+		argDefinitions:    make(map[string]map[int]interface{}),
+
+		typeDefinitions:      make(map[reflect.Type]interface{}),
+		namedTypeDefinitions: make(map[reflect.Type]map[string]interface{}),
+		converter:            Converter.NewConverter(),
 	}
 }
 
 /* Format: PackageName.StructName. */
 func (ij *injector) Make(name string) interface{} {
-	ij.log(fmt.Sprintf("injector asked to provision: '%s' by user", name))
+	if ij.diagnostics {
+		return ij.makeWithDiagnostics(name)
+	}
+
+	return ij.makeType(name)
+}
+
+func (ij *injector) makeType(name string) (made interface{}) {
+	/* Make() can be called by delegates, so the path is continued rather than started again for those. */
+	depth := len(ij.resolutionPath)
+
+	if depth == 0 {
+		ij.resolutionRoot = name
+	}
+
+	ij.resolutionPath = append(ij.resolutionPath, name[strings.LastIndex(name, ".")+1:])
+
+	defer func() {
+		ij.resolutionPath = ij.resolutionPath[:depth]
+	}()
+
+	if ij.observed() {
+		span := ij.startSpan()
+
+		ij.notify(Event{ID: span.id, ParentID: span.parentID, Kind: EventResolveStart, Name: name})
+
+		defer func() {
+			problem := recover()
+
+			ij.endSpan(span, Event{Kind: EventResolveEnd, Name: name, Type: reflect.TypeOf(made)}, problem)
+
+			if problem != nil {
+				panic(problem)
+			}
+		}()
+	}
+
+	if ij.logEnabled(Logger.LevelInfo) {
+		ij.info("Make requested", Logger.Any("name", name))
+
+		defer func(start time.Time) {
+			ij.info("Make finished", Logger.Any("name", name), Logger.Duration(time.Since(start)))
+		}(time.Now())
+	}
 
 	/* Let's check the struct and interface registries. */
 	obj := ij.getObjFromStructOrInterfaceTypeRegistry(name)
@@ -105,8 +329,10 @@ func (ij *injector) Make(name string) interface{} {
 	/* See if this object is already cached? */
 	foundObj := ij.objectCache.FindByValue(reflect.ValueOf(obj))
 
+	ij.emitCacheLookup(foundObj != nil, "", reflect.TypeOf(getValue(obj)))
+
 	if foundObj != nil {
-		ij.log(fmt.Sprintf("Object of type: '%T' was already provisioned in registry - returning.", getValue(foundObj)))
+		ij.debug("Returning shared object", Logger.Type(getValue(foundObj)), Logger.Source(SourceShared))
 
 		return toStructPtr(getValue(foundObj))
 	}
@@ -120,8 +346,10 @@ func (ij *injector) Make(name string) interface{} {
 	/* Provision all child fields of this top level object. */
 	builtObj := ij.buildFields(obj, obj)
 
-	/* Cache the object. */
-	ij.objectCache.Store(toStructPtr(getValue(builtObj)))
+	/* Cache the object, unless issues were recorded in diagnostics mode and it is incomplete. */
+	if ij.diagnosedIssues() == 0 {
+		ij.objectCache.Store(toStructPtr(getValue(builtObj)))
+	}
 
 	return builtObj
 }
@@ -133,16 +361,33 @@ func (ij *injector) Define(objectName string, paramName string, value interface{
 	}
 
 	ij.definitions[objectName][paramName] = value
+
+	ij.invalidatePlans()
 }
 
 /* Define global scalar parameters for injection. */
 func (ij *injector) DefineGlobal(paramName string, value interface{}) {
 	ij.globalDefinitions[paramName] = value
+
+	ij.invalidatePlans()
+}
+
+/* Define delegate and factory arguments for injection. */
+func (ij *injector) DefineArg(factoryOrTypeName string, position int, value interface{}) {
+	if _, found := ij.argDefinitions[factoryOrTypeName]; !found {
+		ij.argDefinitions[factoryOrTypeName] = make(map[int]interface{})
+	}
+
+	ij.argDefinitions[factoryOrTypeName][position] = value
+
+	ij.invalidatePlans()
 }
 
 /* Delegate the initialisation of an object to a factory method. */
 func (ij *injector) Delegate(objectName string, factoryMethod interface{}) {
 	ij.delegates.Store(objectName, factoryMethod)
+
+	ij.invalidatePlans()
 }
 
 func (ij *injector) Bind(interfaceName string, structName string) {
@@ -162,16 +407,32 @@ func (ij *injector) Bind(interfaceName string, structName string) {
 	}
 
 	ij.bindings[interfaceName] = structName
+
+	ij.invalidatePlans()
 }
 
 func (ij *injector) Invoke(object interface{}, methodName string, args ...interface{}) []interface{} {
-	inputs := make([]reflect.Value, len(args))
+	method, err := findMethod(object, methodName)
+
+	if err != nil {
+		ij.panicWithError(err)
+	}
+
+	inputs, err := prepareInvocationArgs(object, methodName, method.Type(), args)
 
-	for i, arg := range args {
-		inputs[i] = reflect.ValueOf(arg)
+	if err != nil {
+		ij.panicWithError(err)
 	}
 
-	results := reflect.ValueOf(object).MethodByName(methodName).Call(inputs)
+	ij.info("Invoking method", Logger.Type(object), Logger.Any("method", methodName), Logger.Any("arguments", len(inputs)))
+
+	var results []reflect.Value
+
+	if method.Type().IsVariadic() {
+		results = method.CallSlice(inputs)
+	} else {
+		results = method.Call(inputs)
+	}
 
 	outputs := make([]interface{}, len(results))
 
@@ -182,59 +443,144 @@ func (ij *injector) Invoke(object interface{}, methodName string, args ...interf
 	return outputs
 }
 
+func (ij *injector) Call(function interface{}, overrides ...interface{}) ([]interface{}, error) {
+	if function == nil || reflect.TypeOf(function).Kind() != reflect.Func {
+		return nil, fmt.Errorf("Call() expects a function or closure, got: %T", function)
+	}
+
+	functionValue := reflect.ValueOf(function)
+
+	inputs, err := ij.resolveCallArgs(function, overrides)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var results []reflect.Value
+
+	if functionValue.Type().IsVariadic() {
+		results = functionValue.CallSlice(inputs)
+	} else {
+		results = functionValue.Call(inputs)
+	}
+
+	return splitErrorResult(functionValue.Type(), results)
+}
+
+func (ij *injector) Shutdown() {
+	cleanups := ij.cleanups
+	ij.cleanups = nil
+
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		ij.info("Calling cleanup function", Logger.Any("position", i))
+
+		cleanups[i]()
+	}
+}
+
 func (ij *injector) Share(obj interface{}) {
 	ij.objectCache.Store(obj)
 }
 
 /* Checks both the struct registry and the interface registry. */
 func (ij *injector) getObjFromStructOrInterfaceTypeRegistry(name string) interface{} {
-	obj := ij.tr.FindStructType(name)
+	resolved, err := ij.resolveTypeName(name)
 
-	if obj != nil {
-		/* Object in registry is a struct - so create a ptr copy so when we pass obj in, it is updated recursively. */
-		return toStructPtr(obj)
+	if err != nil {
+		ij.fail(err)
+	}
+
+	/* Does the interface have a delegate (for when there are no exported structs for that interface)? */
+	if resolved.delegate != nil {
+		return ij.callDelegateOrFactory(resolved.delegate)
+	}
+
+	if resolved.binding == BindingSingleImplementation {
+		ij.debug(
+			"Provisioning single implementation",
+			Logger.Type(resolved.structType),
+			Logger.Any("interface", name),
+			Logger.Source(BindingSingleImplementation),
+		)
+	}
+
+	if resolved.binding != "" {
+		ij.emit(
+			Event{Kind: EventBindingApplied, Name: name, Type: reflect.TypeOf(resolved.structType), Binding: resolved.binding},
+		)
+	}
+
+	/* Object in registry is a struct - so create a ptr copy so when we pass obj in, it is updated recursively. */
+	return toStructPtr(resolved.structType)
+}
+
+/* The struct, or the delegate or factory of an interface, found for a name or an interface, not initialised yet. */
+type typeLookup struct {
+	/* The struct from the registry, when not provided by a delegate or factory. */
+	structType interface{}
+
+	/* The delegate or factory for the interface. */
+	delegate *delegateLookup
+
+	/* How an interface was resolved to the struct, empty for a struct. */
+	binding Binding
+
+	/* The structs implementing the interface, which were candidates for it. */
+	candidates []interface{}
+}
+
+/* Finds the struct or interface for a name given to Make(), without initialising anything. */
+func (ij *injector) compileTypeName(name string) (*typeLookup, error) {
+	if obj := ij.tr.FindStructType(name); obj != nil {
+		return &typeLookup{structType: obj}, nil
 	}
 
 	/* Is it an interface though? */
 	interfaceType := ij.tr.FindInterfaceType(name)
 
 	if interfaceType == nil {
-		ij.panic(fmt.Sprintf("No type found in registry for name: '%s', did you forget to register it?", name))
+		return nil, newFailure(
+			IssueMissingType, "No type found in registry for name: '%s', did you forget to register it?", name,
+		)
 	}
 
+	structTypes := ij.tr.FindStructTypesByInterfaceType(name)
+
 	/* Is the interface bound to a single concrete type via bind()? */
 	if structName, found := ij.bindings[name]; found {
-		return toStructPtr(ij.tr.FindStructType(structName))
+		return &typeLookup{
+			structType: ij.tr.FindStructType(structName), binding: BindingFullName, candidates: structTypes,
+		}, nil
 	}
 
 	/* Does the interface have a delegate (for when there are no exported structs for that interface)? */
-	delegateResults := ij.findAndCallDelegateOrFactory(interfaceType)
+	lookup, err := ij.findDelegateOrFactory(interfaceType)
 
-	if delegateResults != nil {
-		return delegateResults
+	if err != nil {
+		return nil, err
 	}
 
-	/* Interface type exists so search for a single implementing type. If more, user needs to bind one. */
-	structTypes := ij.tr.FindStructTypesByInterfaceType(name)
+	if lookup != nil {
+		return &typeLookup{delegate: lookup, candidates: structTypes}, nil
+	}
 
+	/* Interface type exists so search for a single implementing type. If more, user needs to bind one. */
 	switch lenStructs := len(structTypes); {
 	case lenStructs == 0:
-		ij.panic("You can't Make() an interface unless there is exactly one implementing type in the registry.")
-	case lenStructs > 1:
-		ij.panic(
-			fmt.Sprintf(
-				"Multiple implementing types were found for interface: '%s', specify one with bind()", name,
-			),
+		return nil, newFailure(
+			IssueMissingImplementation,
+			"You can't Make() an interface unless there is exactly one implementing type in the registry.",
 		)
-	default:
-		obj = structTypes[0]
-
-		ij.log(
-			fmt.Sprintf("Single object of type: '%T' implementing: '%s' was found and provisioned", obj, name),
+	case lenStructs > 1:
+		return nil, newFailure(
+			IssueAmbiguousInterface,
+			"Multiple implementing types were found for interface: '%s', specify one with bind()", name,
 		)
 	}
 
-	return toStructPtr(obj)
+	return &typeLookup{
+		structType: structTypes[0], binding: BindingSingleImplementation, candidates: structTypes,
+	}, nil
 }
 
 func (ij *injector) buildFields(topLevelObj interface{}, parentObj interface{}) interface{} {
@@ -244,192 +590,251 @@ func (ij *injector) buildFields(topLevelObj interface{}, parentObj interface{})
 		return topLevelObj
 	}
 
-	for i := 0; i < fieldCount; i++ {
-		fieldName := reflect.TypeOf(getValue(parentObj)).Field(i).Name
-		fieldType := reflect.TypeOf(getValue(parentObj)).Field(i).Type
-		fieldIsPointer := reflect.TypeOf(getValue(parentObj)).Field(i).Type.Kind() == reflect.Ptr
-		valueIsPointer := value.Elem().Kind() == reflect.Ptr
+	plan := ij.structPlan(getElem(parentObj).Type())
+
+	for i := range plan.fields {
+		field := &plan.fields[i]
+
+		ij.diagnose(field.name, func() {
+			ij.buildField(topLevelObj, parentObj, value, plan.structType, field)
+		})
+	}
+
+	return topLevelObj
+}
 
-		var field interface{}
+/* Resolves and sets a field of the parent object, following the plan compiled for it. */
+func (ij *injector) buildField(
+	topLevelObj interface{}, parentObj interface{}, value reflect.Value, structType reflect.Type, plan *fieldPlan,
+) {
+	i := plan.index
+	fieldName := plan.name
+	fieldType := plan.fieldType
+	fieldIsPointer := plan.isPointer
+
+	/* Ignore private fields */
+	if !plan.exported {
+		ij.trace(
+			"Ignoring private field",
+			Logger.FieldName(fieldName),
+			Logger.Type(fieldType),
+			Logger.Any("object", structType),
+		)
 
-		if valueIsPointer {
-			/* Ignore private fields */
-			if !value.Elem().Elem().Field(i).CanSet() {
-				ij.log(
-					fmt.Sprintf(
-						"Found private %s field: %s of type: %s on object: %T, ignoring...",
-						fieldType.Kind(), fieldName, fieldType, parentObj,
-					),
-				)
+		return
+	}
 
-				continue
-			}
+	var field interface{}
 
-			/* Use Addr() to get the actually 'settable' field. */
-			field = value.Elem().Elem().Field(i).Addr().Interface()
-		} else {
-			/* Ignore private fields */
-			if !value.Elem().Field(i).CanInterface() {
-				ij.log(
-					fmt.Sprintf(
-						"Found private %s field: %s of type: %s on object: %T, ignoring...",
-						fieldType.Kind(), fieldName, fieldType, parentObj,
-					),
-				)
-
-				continue
-			}
+	if value.Elem().Kind() == reflect.Ptr {
+		/* Use Addr() to get the actually 'settable' field. */
+		field = value.Elem().Elem().Field(i).Addr().Interface()
+	} else {
+		field = value.Elem().Field(i).Addr().Interface()
+	}
+
+	if ij.logEnabled(Logger.LevelTrace) {
+		ij.trace("Found field", Logger.FieldName(fieldName), Logger.Type(fieldType), Logger.Any("object", structType))
+	}
+
+	/* Interfaces */
+	if fieldType.Kind() == reflect.Interface {
+		/* The user may have defined a specific implementation for this field, or for the interface type. */
+		foundDefinition, definitionSource := plan.definition, plan.definitionSource
+
+		if foundDefinition != nil {
+			ij.setDefinition(getElem(value.Interface()).Field(i), foundDefinition, definitionSource, fieldName, parentObj)
 
-			field = value.Elem().Field(i).Addr().Interface()
+			return
 		}
 
-		ij.log(
-			fmt.Sprintf(
-				"Found %s field: %s of type: %s on object: %T", fieldType.Kind(), fieldName, fieldType, parentObj,
-			),
-		)
+		obj, delegated := ij.provisionTypeFromInterface(fieldType, fieldName)
 
-		/* Interfaces */
-		if fieldType.Kind() == reflect.Interface {
-			obj := ij.provisionTypeFromInterface(fieldType, fieldName)
+		/* We found a single or bound type, great... but do we have this single or bound type already cached? */
+		dep := ij.objectCache.FindByType(reflect.TypeOf(obj))
 
-			/* We found a single or bound type, great... but do we have this single or bound type already cached? */
-			dep := ij.objectCache.FindByType(reflect.TypeOf(obj))
+		ij.emitCacheLookup(dep != nil, fieldName, reflect.TypeOf(obj))
 
-			if dep != nil {
-				ij.log(
-					fmt.Sprintf(
-						"Dependency of type: '%T' was already provisioned in registry - returning.", getValue(dep),
-					),
-				)
+		if dep != nil {
+			ij.debug(
+				"Injecting shared object",
+				Logger.FieldName(fieldName),
+				Logger.Type(getValue(dep)),
+				Logger.Source(SourceShared),
+			)
 
-				getElem(value.Interface()).Field(i).Set(reflect.ValueOf(toStructPtr(getValue(dep))))
+			getElem(value.Interface()).Field(i).Set(reflect.ValueOf(toStructPtr(getValue(dep))))
 
-				/* Cache the dependency now - it wasn't created by a factory so it's okay to cache it. */
-				ij.objectCache.Store(dep)
+			/* Cache the dependency now - it wasn't created by a factory so it's okay to cache it. */
+			ij.objectCache.Store(dep)
 
-				continue
-			}
+			return
+		}
+
+		/* The interface's delegate has already built it, so it must not be looked up (and called) again. */
+		var delegateOrFactoryResult interface{}
 
+		if !delegated {
 			/* Any user-registered delegates or automatic factories available for it? */
-			delegateOrFactoryResult := ij.findAndCallDelegateOrFactory(obj)
+			delegateOrFactoryResult = ij.findAndCallDelegateOrFactory(obj)
 
-			if delegateOrFactoryResult != nil {
-				ij.log(fmt.Sprintf("Found delegate for type: %T. Delegate called and returned: %T", obj, delegateOrFactoryResult))
+			/* Okay, are there any factories available for the INTERFACE instead? */
+			if delegateOrFactoryResult == nil {
+				delegateOrFactoryResult = ij.findAndCallDelegateOrFactory(field)
+			}
 
+			if delegateOrFactoryResult != nil {
 				obj = delegateOrFactoryResult
 			}
+		}
 
-			/* Okay, are there any factories available for the INTERFACE instead? */
-			if delegateOrFactoryResult == nil {
-				// @todo changed this from fieldType to field, does it work?
-				delegateOrFactoryResult = ij.findAndCallDelegateOrFactory(field)
+		obj = toStructPtr(obj)
 
-				if delegateOrFactoryResult != nil {
-					ij.log(fmt.Sprintf("Found delegate for type: %T. Delegate called and returned: %T", obj, delegateOrFactoryResult))
+		/* Built before the field is set to a copy of it, unless a delegate or factory has already built it. */
+		if !delegated && delegateOrFactoryResult == nil {
+			ij.buildFields(topLevelObj, obj)
+		}
 
-					obj = delegateOrFactoryResult
-				}
-			}
+		getElem(value.Interface()).Field(i).Set(reflect.ValueOf(toStructPtr(getValue(obj))))
 
-			obj = toStructPtr(obj)
+		return
+	}
 
-			getElem(value.Interface()).Field(i).Set(reflect.ValueOf(toStructPtr(getValue(obj))))
+	/* Scalars */
+	if !fieldIsPointer && fieldType.Kind() != reflect.Struct || (fieldIsPointer && fieldType.Elem().Kind() != reflect.Struct) {
+		foundDefinition, definitionSource := plan.definition, plan.definitionSource
 
-			ij.buildFields(topLevelObj, obj)
+		if foundDefinition != nil {
+			ij.setDefinition(getElem(value.Interface()).Field(i), foundDefinition, definitionSource, fieldName, parentObj)
 
-			continue
+			return
 		}
 
-		/* Scalars */
-		if !fieldIsPointer && fieldType.Kind() != reflect.Struct || (fieldIsPointer && fieldType.Elem().Kind() != reflect.Struct) {
-			foundDefinition := ij.findDefinitionOrGlobalDefinition(value, fieldName)
+		/* Has the field asked for an environment variable with the `env` tag? */
+		structField := structType.Field(i)
 
-			if foundDefinition != nil {
-				getElem(value.Interface()).Field(i).Set(reflect.ValueOf(foundDefinition))
+		if envValue, found := ij.findEnvTagValue(structField, parentObj); found {
+			getElem(value.Interface()).Field(i).Set(envValue)
+		}
 
-				continue
-			}
+		/* We don't want to recurse with buildFields for user-provided definitions. */
+		return
+	}
 
-			/* We don't want to recurse with buildFields for user-provided definitions. */
-			continue
-		}
+	/* If the user has defined a specific injection definition, use this... comes first so overrides Share(). */
+	foundDefinition, definitionSource := plan.definition, plan.definitionSource
 
-		/* If the user has defined a specific injection definition, use this... comes first so overrides Share(). */
-		foundDefinition := ij.findDefinitionOrGlobalDefinition(value, fieldName)
+	if foundDefinition != nil {
+		ij.setDefinition(getElem(value.Interface()).Field(i), foundDefinition, definitionSource, fieldName, parentObj)
 
-		if foundDefinition != nil {
-			ij.log(
-				fmt.Sprintf(
-					"Definition of type: '%T' was found for object: %T - injecting.", foundDefinition, value,
-				),
-			)
+		/* We don't want to recurse with buildFields for user-provided definitions. */
+		return
+	}
 
-			getElem(value.Interface()).Field(i).Set(reflect.ValueOf(foundDefinition))
+	/* Has the object already been cached by the user? */
+	dep := ij.objectCache.FindByType(fieldType)
 
-			/* We don't want to recurse with buildFields for user-provided definitions. */
-			continue
+	ij.emitCacheLookup(dep != nil, fieldName, fieldType)
+
+	if dep != nil {
+		ij.debug(
+			"Injecting shared object",
+			Logger.FieldName(fieldName),
+			Logger.Type(getValue(dep)),
+			Logger.Source(SourceShared),
+		)
+
+		if fieldIsPointer {
+			getElem(value.Interface()).Field(i).Set(reflect.ValueOf(toStructPtr(getValue(dep))))
+		} else {
+			getElem(value.Interface()).Field(i).Set(reflect.ValueOf(getValue(dep)))
 		}
 
-		/* Has the object already been cached by the user? */
-		dep := ij.objectCache.FindByType(fieldType)
+		/* Cache the dependency now - we don't want to cache factory results below as they may be dynamic. */
+		ij.objectCache.Store(dep)
 
-		if dep != nil {
-			ij.log(
-				fmt.Sprintf(
-					"Dependency of type: '%T' was already provisioned in registry - returning.", getValue(dep),
-				),
-			)
+		return
+	}
 
-			if fieldIsPointer {
-				getElem(value.Interface()).Field(i).Set(reflect.ValueOf(toStructPtr(getValue(dep))))
-			} else {
-				getElem(value.Interface()).Field(i).Set(reflect.ValueOf(getValue(dep)))
-			}
+	delegateOrFactory := ij.findAndCallDelegateOrFactory(fieldType)
 
-			/* Cache the dependency now - we don't want to cache factory results below as they may be dynamic. */
-			ij.objectCache.Store(dep)
+	if delegateOrFactory != nil {
+		dep = delegateOrFactory
+	} else {
+		/* Object has not been cached by the user nor is there a factory for it - initialise. */
+		dep = ij.findStructTypeByType(fieldType)
+	}
 
-			continue
-		}
+	if dep == nil {
+		ij.fail(
+			newFailure(
+				IssueMissingType, "No type found in registry for name: '%s', did you forget to register it?", fieldName,
+			),
+		)
+	}
 
-		delegateOrFactory := ij.findAndCallDelegateOrFactory(fieldType)
+	if fieldIsPointer {
+		getElem(value.Interface()).Field(i).Set(reflect.ValueOf(toStructPtr(getElem(dep).Interface())))
+	} else {
+		getElem(value.Interface()).Field(i).Set(getElem(dep))
+	}
 
-		if delegateOrFactory != nil {
-			dep = delegateOrFactory
-		} else {
-			/* Object has not been cached by the user nor is there a factory for it - initialise. */
-			dep = ij.tr.FindStructTypeByType(fieldType)
-		}
+	/* If a factory has returned an object, we don't need to recurse on it as the user has decided to build it. */
+	if delegateOrFactory == nil {
+		ij.buildFields(topLevelObj, field)
+	}
+}
 
-		if dep == nil {
-			ij.panic(fmt.Sprintf("No type found in registry for name: '%s', did you forget to register it?", fieldName))
-		}
+/*
+On encountering a field asking for an interface, try and figure out which struct to inject. Also returns whether the
+interface's delegate built the object, in which case it must not be built or delegated again.
+*/
+func (ij *injector) provisionTypeFromInterface(fieldType reflect.Type, fieldName string) (interface{}, bool) {
+	resolved, err := ij.resolveInterface(fieldType, fieldName)
 
-		if fieldIsPointer {
-			getElem(value.Interface()).Field(i).Set(reflect.ValueOf(toStructPtr(getElem(dep).Interface())))
-		} else {
-			getElem(value.Interface()).Field(i).Set(getElem(dep))
-		}
+	if err != nil {
+		ij.fail(err)
+	}
 
-		/* If a factory has returned an object, we don't need to recurse on it as the user has decided to build it. */
-		if delegateOrFactory == nil {
-			ij.buildFields(topLevelObj, field)
-		}
+	/* Does the interface have a delegate (for when there are no exported structs for that interface)? */
+	if resolved.delegate != nil {
+		return ij.callDelegateOrFactory(resolved.delegate), true
 	}
 
-	return topLevelObj
+	ij.emit(
+		Event{
+			Kind:    EventBindingApplied,
+			Name:    fieldType.String(),
+			Field:   fieldName,
+			Type:    reflect.TypeOf(resolved.structType),
+			Binding: resolved.binding,
+		},
+	)
+
+	if resolved.binding != BindingSingleImplementation {
+		return resolved.structType, false
+	}
+
+	obj := toStructPtr(resolved.structType)
+
+	ij.debug(
+		"Provisioning single implementation",
+		Logger.FieldName(fieldName),
+		Logger.Type(obj),
+		Logger.Any("interface", fieldType),
+		Logger.Source(BindingSingleImplementation),
+	)
+
+	return obj, false
 }
 
-/* On encountering a field asking for an interface, try and figure out which struct to inject. */
-func (ij *injector) provisionTypeFromInterface(fieldType reflect.Type, fieldName string) interface{} {
+/* Finds the struct, or delegate or factory, for an interface field or argument, without initialising anything. */
+func (ij *injector) compileInterface(fieldType reflect.Type, fieldName string) (*typeLookup, error) {
 	interfaceType := ij.tr.FindInterfaceTypeByType(fieldType)
 
 	if interfaceType == nil {
-		ij.panic(
-			fmt.Sprintf(
-				"No interface found in registry for name: '%s', did you forget to register it?", fieldName,
-			),
+		return nil, newFailure(
+			IssueMissingType, "No interface found in registry for name: '%s', did you forget to register it?", fieldName,
 		)
 	}
 
@@ -439,160 +844,299 @@ func (ij *injector) provisionTypeFromInterface(fieldType reflect.Type, fieldName
 	/* Interface type exists so search for a single implementing type. If more exist, user needs to bind one. */
 	structTypes := ij.tr.FindStructTypesByInterfaceType(fullInterfaceName)
 
-	var obj interface{}
-
 	/* Is the interface bound to a single concrete type via bind()? */
 	if structName, found := ij.bindings[fullInterfaceName]; found {
-		return ij.tr.FindStructType(structName)
+		return &typeLookup{
+			structType: ij.tr.FindStructType(structName), binding: BindingFullName, candidates: structTypes,
+		}, nil
 	}
 
 	/* What about a short name for the interface? */
 	if structName, found := ij.bindings[fieldType.Name()]; found {
-		return ij.tr.FindStructType(structName)
+		return &typeLookup{
+			structType: ij.tr.FindStructType(structName), binding: BindingShortName, candidates: structTypes,
+		}, nil
 	}
 
 	/* Does the interface have a delegate (for when there are no exported structs for that interface)? */
-	delegateOrFactoryResult := ij.findAndCallDelegateOrFactory(interfaceType)
+	lookup, err := ij.findDelegateOrFactory(interfaceType)
+
+	if err != nil {
+		return nil, err
+	}
 
-	if delegateOrFactoryResult != nil {
-		return delegateOrFactoryResult
+	if lookup != nil {
+		return &typeLookup{delegate: lookup, candidates: structTypes}, nil
 	}
 
 	switch lenStructs := len(structTypes); {
 	case lenStructs == 0:
-		ij.panic(
-			"Could not initialise interface dependency unless there is exactly one implementing type in " +
+		return nil, newFailure(
+			IssueMissingImplementation,
+			"Could not initialise interface dependency unless there is exactly one implementing type in "+
 				"the registry or it has been bound to a single type with bind().",
 		)
 	case lenStructs > 1:
-		ij.panic(
-			fmt.Sprintf(
-				"Multiple implementing types were found for interface: '%s', specify one with bind()",
-				fullInterfaceName,
-			),
-		)
-	default:
-		obj = toStructPtr(structTypes[0])
-
-		ij.log(
-			fmt.Sprintf(
-				"Found single mapping of: '%T' implementing: '%s', provisioning", obj, fullInterfaceName,
-			),
+		return nil, newFailure(
+			IssueAmbiguousInterface,
+			"Multiple implementing types were found for interface: '%s', specify one with bind()", fullInterfaceName,
 		)
 	}
 
-	return obj
+	return &typeLookup{
+		structType: structTypes[0], binding: BindingSingleImplementation, candidates: structTypes,
+	}, nil
 }
 
-func (ij *injector) findDefinitionOrGlobalDefinition(value reflect.Value, fieldName string) interface{} {
-	/* Is there a short name available (without the package path, so "testObject"). ? */
-	shortName := value.Type().Elem().Name()
+/*
+Finds a definition for a field in order of specificity: the object definitions, the definitions for the type and name
+of the field, the global definitions and then the definitions for the type of the field.
 
-	if definition, found := ij.definitions[shortName]; found {
-		if definitionVal, found := definition[fieldName]; found {
-			return definitionVal
+Global definitions are only matched on name, so they are ignored for fields whose type they can't be assigned to.
+*/
+func (ij *injector) findDefinitionOrGlobalDefinition(
+	structType reflect.Type, fieldName string, fieldType reflect.Type,
+) (interface{}, Source) {
+	for _, candidate := range ij.findDefinitionCandidates(structType, fieldName, fieldType) {
+		if candidate.usable {
+			return candidate.value, candidate.source
 		}
+
+		ij.warn(
+			"Ignoring global definition",
+			Logger.FieldName(fieldName),
+			Logger.Type(fieldType),
+			Logger.Any("definition", Logger.TypeOf(candidate.value)),
+		)
 	}
 
-	/* How about the long name? */
-	var valueName string
+	return nil, ""
+}
 
-	valueIsPointer := value.Elem().Kind() == reflect.Ptr
+/* A definition matching a field, which can't be used if it is a global definition for a field of another type. */
+type definitionCandidate struct {
+	value  interface{}
+	source Source
+	usable bool
+}
 
-	if valueIsPointer {
-		valueName = fmt.Sprintf("%s.%s", value.Type().PkgPath(), value.Type().Name())
-	} else {
-		valueName = fmt.Sprintf("%s.%s", value.Type().Elem().PkgPath(), value.Type().Elem().Name())
+/* Finds every definition matching a field, in order of specificity, so that the ones not used can be explained. */
+func (ij *injector) findDefinitionCandidates(
+	structType reflect.Type, fieldName string, fieldType reflect.Type,
+) (candidates []definitionCandidate) {
+	/* Is there a short name available (without the package path, so "testObject"), or the long name? */
+	for _, structName := range []string{structType.Name(), structType.PkgPath() + "." + structType.Name()} {
+		if definition, found := ij.definitions[structName]; found {
+			if definitionVal, found := definition[fieldName]; found {
+				candidates = append(candidates, definitionCandidate{definitionVal, SourceDefinition, true})
+			}
+		}
 	}
 
-	if definition, found := ij.definitions[valueName]; found {
-		if definitionVal, found := definition[fieldName]; found {
-			return definitionVal
-		}
+	/* Has the type been defined for fields with this name? */
+	if definitionVal, found := ij.findTypeDefinition(fieldType, fieldName); found {
+		candidates = append(candidates, definitionCandidate{definitionVal, SourceNamedTypeDefinition, true})
 	}
 
 	/* Is there a globally available injection definition?  */
 	if definitionVal, found := ij.globalDefinitions[fieldName]; found {
-		return definitionVal
+		_, err := ij.converter.Convert(definitionVal, fieldType)
+
+		candidates = append(candidates, definitionCandidate{definitionVal, SourceGlobalDefinition, err == nil})
+	}
+
+	/* Has the type been defined for all fields? */
+	if definitionVal, found := ij.findTypeDefinition(fieldType, ""); found {
+		candidates = append(candidates, definitionCandidate{definitionVal, SourceTypeDefinition, true})
+	}
+
+	return candidates
+}
+
+/* Sets a definition on a field, converting it first to the field's type so that the user gets a clear error. */
+func (ij *injector) setDefinition(
+	field reflect.Value, definition interface{}, source Source, fieldName string, parentObj interface{},
+) {
+	definitionValue, err := ij.converter.Convert(definition, field.Type())
+
+	if err != nil {
+		ij.fail(
+			newFailure(
+				IssueDefinitionMismatch,
+				"Definition for field: '%s' on object: %T could not be injected: %s", fieldName, parentObj, err.Error(),
+			),
+		)
 	}
 
-	return nil
+	if ij.logEnabled(Logger.LevelDebug) {
+		ij.debug("Injecting definition", Logger.FieldName(fieldName), Logger.Type(definition), Logger.Source(source))
+	}
+
+	if ij.observed() {
+		ij.emit(Event{Kind: EventDefinitionApplied, Field: fieldName, Type: field.Type(), Source: source})
+	}
+
+	field.Set(definitionValue)
+}
+
+/* The delegate or automatic factory found for a type, which has not been called yet. */
+type delegateLookup struct {
+	/* A pointer to the user-provided delegate, or the automatic factory function from the registry. */
+	function interface{}
+
+	/* Whether the function is an automatic factory from the registry rather than a user-provided delegate. */
+	isFactory bool
+
+	/* The name the delegate or factory was found by. */
+	name string
 }
 
 func (ij *injector) findAndCallDelegateOrFactory(objType interface{}) interface{} {
-	/* Any user-registered delegates for it? */
-	userProvidedDelegate := ij.delegates.FindByType(reflect.TypeOf(objType))
+	lookup, err := ij.findDelegateOrFactory(objType)
+
+	if err != nil {
+		ij.fail(err)
+	}
 
-	if userProvidedDelegate != nil {
-		return ij.callDelegate(userProvidedDelegate)
+	if lookup == nil {
+		return nil
 	}
 
-	/* Automatic factory usage possible? */
-	typeName := fmt.Sprintf("%s.%s", getElem(objType).Type().PkgPath(), getElem(objType).Type().Name())
+	return ij.callDelegateOrFactory(lookup)
+}
 
-	/* Did we pass in a reflect.Type? Easier than checking the type of the type of the type etc.. */
-	if typeName == "reflect.rtype" {
-		/* We can have a **reflect.rtype, don't ask me why. I lost that a long time ago in this craziness. */
-		objType = getElem(objType).Addr().Interface()
+/* Calls a delegate or factory that has been found, resolving it's arguments. */
+func (ij *injector) callDelegateOrFactory(lookup *delegateLookup) (result interface{}) {
+	source := SourceDelegate
 
-		assertedType := objType.(reflect.Type)
+	if lookup.isFactory {
+		source = SourceFactory
+	}
 
-		typeName = fmt.Sprintf("%s.%s", assertedType.PkgPath(), assertedType.Name())
+	if ij.logEnabled(Logger.LevelDebug) {
+		defer func(start time.Time) {
+			ij.debug("Called "+string(source), Logger.Type(lookup.function), Logger.Duration(time.Since(start)))
+		}(time.Now())
+	}
 
-		/*
-			If this is an interface, but the type is private, Name() will be lowercased so won't be found:
+	if ij.observed() {
+		span := ij.startSpan()
 
-			ie: Injector interface, but here assertedType.Name() will be "injector"..
-		*/
+		defer func() {
+			problem := recover()
 
-		userProvidedDelegate = ij.delegates.FindByName(typeName)
+			kind := EventDelegateCalled
 
-		if userProvidedDelegate != nil {
-			return ij.callDelegate(userProvidedDelegate)
-		}
+			if lookup.isFactory {
+				kind = EventFactoryCalled
+			}
 
-		/* What about user-provided short-names? */
-		assertedTypeName := assertedType.Name()
+			ij.endSpan(
+				span,
+				Event{Kind: kind, Name: lookup.name, Type: reflect.TypeOf(result), Function: lookup.functionName()},
+				problem,
+			)
+
+			if problem != nil {
+				panic(problem)
+			}
+		}()
+	}
 
-		userProvidedDelegate = ij.delegates.FindByName(assertedTypeName)
+	function := reflect.ValueOf(lookup.function)
 
-		if userProvidedDelegate != nil {
-			return ij.callDelegate(userProvidedDelegate)
-		}
+	/* User-provided delegates are stored as a pointer to the function. */
+	if !lookup.isFactory {
+		function = function.Elem()
 	}
 
-	/* What about user-provided short-names? */
-	userProvidedDelegate = ij.delegates.FindByName(getElem(objType).Type().Name())
+	result, cleanup, err := splitFactoryResults(function.Type(), function.Call(ij.resolveInvocationArgs(lookup.function)))
+
+	if err != nil {
+		ij.fail(
+			&resolutionFailure{
+				kind:    IssueFactoryError,
+				message: fmt.Sprintf("The %s: %s returned an error: %s", source, lookup.functionName(), err.Error()),
+				err:     err,
+			},
+		)
+	}
 
-	if userProvidedDelegate != nil {
-		return ij.callDelegate(userProvidedDelegate)
+	if cleanup != nil {
+		ij.cleanups = append(ij.cleanups, cleanup)
 	}
 
-	factoryDelegate := ij.getFactoryFromFactoryRegistry(typeName)
+	return result
+}
 
-	if factoryDelegate != nil {
-		ij.log(fmt.Sprintf("Found single factory delegate automatically in registry: %T", factoryDelegate))
+/*
+Splits the results of a delegate or factory into the object it made, the cleanup function for the object and the error,
+for functions like "func() (*DB, func(), error)". The cleanup function and error are optional, in that order.
+*/
+func splitFactoryResults(functionType reflect.Type, results []reflect.Value) (interface{}, func(), error) {
+	var cleanup func()
+	var err error
 
-		args := ij.resolveInvocationArgs(factoryDelegate)
+	numOut := functionType.NumOut()
 
-		if len(args) > 0 {
-			ij.log(fmt.Sprintf("Ready to inject args: %v into factory delegate: %T", args, factoryDelegate))
+	if numOut > 1 && functionType.Out(numOut-1) == errorType {
+		if errValue := results[numOut-1]; !errValue.IsNil() {
+			err = errValue.Interface().(error)
 		}
 
-		factoryReturns := reflect.ValueOf(factoryDelegate).Call(args)
+		numOut--
+	}
 
-		return factoryReturns[0].Interface()
+	if numOut > 1 && functionType.Out(numOut-1) == cleanupType && err == nil {
+		cleanup, _ = results[numOut-1].Interface().(func())
 	}
 
-	return nil
+	return results[0].Interface(), cleanup, err
 }
 
-func (ij *injector) callDelegate(delegate interface{}) interface{} {
-	factoryReturns := reflect.ValueOf(delegate).Elem().Call(
-		ij.resolveInvocationArgs(delegate),
-	)
+/*
+Finds the user-provided delegate for a type, by it's full name then it's short name, or otherwise the single automatic
+factory in the registry. Nothing is called, so that the same decision can be made when verifying the dependency graph.
+
+An error is returned when more than one factory exists and no delegate has been provided.
+*/
+func (ij *injector) compileDelegateOrFactory(lookupType reflect.Type) (*delegateLookup, error) {
+	fullName := fmt.Sprintf("%s.%s", lookupType.PkgPath(), lookupType.Name())
+
+	/*
+		If this is an interface, but the type is private, Name() will be lowercased so won't be found:
+
+		ie: Injector interface, but here lookupType.Name() will be "injector"..
+	*/
+	for _, name := range []string{fullName, lookupType.Name()} {
+		if userProvidedDelegate := ij.delegates.FindByName(name); userProvidedDelegate != nil {
+			return &delegateLookup{function: userProvidedDelegate, name: name}, nil
+		}
+	}
+
+	factoryDelegate, err := ij.getFactoryFromFactoryRegistry(fullName)
+
+	if err != nil || factoryDelegate == nil {
+		return nil, err
+	}
+
+	return &delegateLookup{function: factoryDelegate, isFactory: true, name: fullName}, nil
+}
+
+/* The type to look up delegates and factories for, given an object, a pointer to a field or a reflect.Type. */
+func delegateLookupType(objType interface{}) reflect.Type {
+	value := reflect.ValueOf(objType)
+
+	/* We can have a **reflect.rtype, don't ask me why. I lost that a long time ago in this craziness. */
+	for value.Kind() == reflect.Ptr {
+		if assertedType, isType := value.Interface().(reflect.Type); isType {
+			return derefType(assertedType)
+		}
+
+		value = value.Elem()
+	}
 
-	return factoryReturns[0].Interface()
+	return value.Type()
 }
 
 /* Resolves the invocation args for a provided function type. */
@@ -612,165 +1156,263 @@ func (ij *injector) resolveInvocationArgs(object interface{}) (results []reflect
 	numArguments := objectType.NumIn()
 
 	if numArguments == 0 {
-		ij.log(fmt.Sprintf("No invocation args required for delegate: %T", object))
-
 		return nil
 	}
 
-	ij.log(fmt.Sprintf("Resolving invocation args for delegate: %T", object))
+	ij.trace("Resolving arguments", Logger.Type(object), Logger.Any("arguments", numArguments))
+
+	diagnosedIssues := ij.diagnosedIssues()
 
 	for i := 0; i < numArguments; i++ {
-		arg := objectType.In(i)
-
-		/* Argument names cannot be retrieved with reflection for functions, so they must be the zero value instead. */
-		if (arg.Kind() != reflect.Interface && arg.Kind() != reflect.Struct && arg.Kind() != reflect.Ptr) ||
-			(arg.Kind() == reflect.Ptr && arg.Elem().Kind() != reflect.Interface && arg.Elem().Kind() != reflect.Struct) {
-			ij.log(
-				fmt.Sprintf(
-					"Encountered scalar delegate argument: %T for delegate: %T, injecting zero value", arg, object,
-				),
-			)
+		ij.diagnose(ij.argPathName(object, i), func() {
+			results = append(results, ij.resolveInvocationArg(object, objectType, i))
+		})
+	}
 
-			/* In the case it's a pointer to a scalar... like *int64... */
-			if arg.Kind() == reflect.Ptr && arg.Elem().Kind() != reflect.Struct {
-				results = append(results, reflect.New(arg.Elem()))
-			} else {
-				results = append(results, reflect.New(arg).Elem())
-			}
+	/* The delegate can't be called without all of it's arguments. */
+	if ij.diagnosedIssues() > diagnosedIssues {
+		panic(errIssuesRecorded)
+	}
 
-			continue
+	return
+}
+
+/* Resolves a single invocation arg, at the given position, for a provided function type. */
+func (ij *injector) resolveInvocationArg(object interface{}, objectType reflect.Type, i int) reflect.Value {
+	arg := objectType.In(i)
+
+	/* Has the user defined the argument at this position with DefineArg()? */
+	if definition, found := ij.findArgDefinition(object, objectType, i); found {
+		return ij.toDefinedArgValue(definition, SourceArgDefinition, object, i)
+	}
+
+	/* Or by it's type, or it's name if the registry has the argument names? */
+	if definition, source, found := ij.findNamedArgDefinition(object, i); found {
+		return ij.toDefinedArgValue(definition, source, object, i)
+	}
+
+	/* Argument names cannot be retrieved with reflection, so without a definition scalars must be the zero value. */
+	if (arg.Kind() != reflect.Interface && arg.Kind() != reflect.Struct && arg.Kind() != reflect.Ptr) ||
+		(arg.Kind() == reflect.Ptr && arg.Elem().Kind() != reflect.Interface && arg.Elem().Kind() != reflect.Struct) {
+		if ij.logEnabled(Logger.LevelTrace) {
+			ij.trace(
+				"Injecting zero value",
+				Logger.FieldName(ij.argPathName(object, i)),
+				Logger.Type(arg),
+				Logger.Source(SourceZeroValue),
+			)
 		}
 
-		/* If interface - resolve interface to struct first.. */
-		if arg.Kind() == reflect.Interface {
-			argFQName := fmt.Sprintf("%s.%s", arg.PkgPath(), arg.Name())
+		/* In the case it's a pointer to a scalar... like *int64... */
+		if arg.Kind() == reflect.Ptr && arg.Elem().Kind() != reflect.Struct {
+			return reflect.New(arg.Elem())
+		}
 
-			ij.log(fmt.Sprintf("Encountered interface delegate argument: %s for delegate: %T", argFQName, object))
+		return reflect.New(arg).Elem()
+	}
 
-			/* Check if there is a delegate specifically for this interface first... */
-			if delegateOrFactoryResult := ij.findAndCallDelegateOrFactory(arg); delegateOrFactoryResult != nil {
-				ij.log(
-					fmt.Sprintf(
-						"Retrieved delegate or factory result: %T, for delegate interface argument: %v, for delegate: %T",
-						delegateOrFactoryResult, arg, object,
-					),
-				)
+	/* If interface - resolve interface to struct first.. */
+	if arg.Kind() == reflect.Interface {
+		argFQName := fmt.Sprintf("%s.%s", arg.PkgPath(), arg.Name())
 
-				// @todo - Depending on pointer or not??
+		/* Check if there is a delegate specifically for this interface first... */
+		if delegateOrFactoryResult := ij.findAndCallDelegateOrFactory(arg); delegateOrFactoryResult != nil {
+			// @todo - Depending on pointer or not??
 
-				results = append(results, reflect.ValueOf(delegateOrFactoryResult))
+			return reflect.ValueOf(delegateOrFactoryResult)
+		}
 
-				continue
+		if resolvedStruct, _ := ij.provisionTypeFromInterface(arg, argFQName); resolvedStruct != nil {
+			/* Found struct type from type registry - replace interface in arg var and continue. */
+			if reflect.TypeOf(resolvedStruct).Kind() == reflect.Ptr && arg.Kind() != reflect.Ptr {
+				arg = reflect.TypeOf(resolvedStruct).Elem()
+			} else {
+				arg = reflect.TypeOf(resolvedStruct)
 			}
 
-			if resolvedStruct := ij.provisionTypeFromInterface(arg, argFQName); resolvedStruct != nil {
-				/* Found struct type from type registry - replace interface in arg var and continue. */
-				if reflect.TypeOf(resolvedStruct).Kind() == reflect.Ptr && arg.Kind() != reflect.Ptr {
-					arg = reflect.TypeOf(resolvedStruct).Elem()
-				} else {
-					arg = reflect.TypeOf(resolvedStruct)
-				}
-
-				/*
-					If the argument is the same as the return type from a delegate, it'll be infinitely recursive so avoid..
+			/*
+				If the argument is the same as the return type from a delegate, it'll be infinitely recursive so avoid..
 
-					Naively assumes factories only return one object of the type we want...
-				*/
+				Naively assumes factories only return one object of the type we want...
+			*/
+			if objectType.NumOut() > 0 {
 				returnValue := objectType.Out(0)
 
 				if strings.ToLower(arg.String()) == strings.ToLower(returnValue.String()) {
-					results = append(results, reflect.ValueOf(resolvedStruct))
-
-					continue
+					return reflect.ValueOf(resolvedStruct)
 				}
 			}
 		}
+	}
+
+	/* Use cached arg if one exists.. */
+	obj := ij.objectCache.FindByType(arg)
+
+	/* Looking up the name of the argument is not free, so only do it when it's going to be used. */
+	if ij.observed() {
+		ij.emitCacheLookup(obj != nil, ij.argPathName(object, i), arg)
+	}
+
+	if obj != nil {
+		if ij.logEnabled(Logger.LevelDebug) {
+			ij.debug(
+				"Injecting shared object",
+				Logger.FieldName(ij.argPathName(object, i)),
+				Logger.Type(obj),
+				Logger.Source(SourceShared),
+			)
+		}
+
+		if arg.Kind() == reflect.Ptr && reflect.TypeOf(obj).Elem().Kind() != reflect.Ptr {
+			return reflect.ValueOf(obj)
+		}
+
+		/* Cached things look like **elem, and the delegate arg is not a pointer. */
+		if arg.Kind() == reflect.Struct && reflect.TypeOf(obj).Elem().Kind() == reflect.Ptr {
+			return getElem(obj)
+		}
+
+		return reflect.ValueOf(obj).Elem()
+	}
+
+	/* User delegate or factory? This is effectively a recursive call... */
+	if delegateOrFactoryResult := ij.findAndCallDelegateOrFactory(arg); delegateOrFactoryResult != nil {
+		/* In the case that the argument is an interface but we have a struct... */
+		if arg.Kind() == reflect.Interface && reflect.TypeOf(delegateOrFactoryResult).Kind() == reflect.Struct {
+			delegateOrFactoryResult = reflect.ValueOf(reflect.PtrTo(reflect.TypeOf(delegateOrFactoryResult))).Interface()
+		} else if objectType.In(i).Kind() == reflect.Struct && reflect.TypeOf(delegateOrFactoryResult).Kind() == reflect.Ptr {
+			delegateOrFactoryResult = reflect.ValueOf(delegateOrFactoryResult).Elem().Interface()
+		}
+
+		return reflect.ValueOf(delegateOrFactoryResult)
+	}
+
+	newArg := reflect.New(derefType(arg)).Interface()
+
+	if ij.logEnabled(Logger.LevelTrace) {
+		ij.trace(
+			"Initialising argument",
+			Logger.FieldName(ij.argPathName(object, i)),
+			Logger.Type(newArg),
+			Logger.Source(SourceNew),
+		)
+	}
+
+	ij.buildFields(newArg, newArg)
+
+	/* Built through a pointer, as the fields of a struct value can't be set. */
+	if objectType.In(i).Kind() == reflect.Struct {
+		return reflect.ValueOf(newArg).Elem()
+	}
+
+	return reflect.ValueOf(newArg)
+}
 
-		/* Use cached arg if one exists.. */
-		if obj := ij.objectCache.FindByType(arg); obj != nil {
-			ij.log(fmt.Sprintf("Encountered cached delegate argument: %T for delegate: %T", obj, object))
+/* Resolves the args for Call(), using the overrides first and recovering any resolution panic as an error. */
+func (ij *injector) resolveCallArgs(function interface{}, overrides []interface{}) (inputs []reflect.Value, err error) {
+	functionType := reflect.TypeOf(function)
+	depth := len(ij.resolutionPath)
 
-			if arg.Kind() == reflect.Ptr && reflect.TypeOf(obj).Elem().Kind() != reflect.Ptr {
-				results = append(results, reflect.ValueOf(obj))
+	if depth == 0 {
+		ij.resolutionRoot = functionType.String()
+	}
+
+	defer func() {
+		ij.resolutionPath = ij.resolutionPath[:depth]
 
-				continue
+		if r := recover(); r != nil {
+			/* Keep errors, like those returned by factories, so that they can be checked with errors.Is() and As(). */
+			if cause, isError := r.(error); isError {
+				err = fmt.Errorf("Unable to resolve arguments for function: %s, error: %w", functionType, cause)
+			} else {
+				err = fmt.Errorf("Unable to resolve arguments for function: %s, error: %v", functionType, r)
 			}
+		}
+	}()
 
-			/* Cached things look like **elem, and the delegate arg is not a pointer. */
-			if arg.Kind() == reflect.Struct && reflect.TypeOf(obj).Elem().Kind() == reflect.Ptr {
-				results = append(results, getElem(obj))
+	usedOverrides := make([]bool, len(overrides))
 
-				continue
+	for i := 0; i < functionType.NumIn(); i++ {
+		if override, found := findOverride(functionType.In(i), overrides, usedOverrides); found {
+			if ij.logEnabled(Logger.LevelDebug) {
+				ij.debug("Using override", Logger.FieldName(ij.argPathName(function, i)), Logger.Type(override.Type()))
 			}
 
-			results = append(results, reflect.ValueOf(obj).Elem())
+			inputs = append(inputs, override)
 
 			continue
 		}
 
-		/* User delegate or factory? This is effectively a recursive call... */
-		if delegateOrFactoryResult := ij.findAndCallDelegateOrFactory(arg); delegateOrFactoryResult != nil {
-			/* In the case that the argument is an interface but we have a struct... */
-			if arg.Kind() == reflect.Interface && reflect.TypeOf(delegateOrFactoryResult).Kind() == reflect.Struct {
-				delegateOrFactoryResult = reflect.ValueOf(reflect.PtrTo(reflect.TypeOf(delegateOrFactoryResult))).Interface()
-			} else if objectType.In(i).Kind() == reflect.Struct && reflect.TypeOf(delegateOrFactoryResult).Kind() == reflect.Ptr {
-				delegateOrFactoryResult = reflect.ValueOf(delegateOrFactoryResult).Elem().Interface()
-			}
+		/* The path of any error starts with the type of the argument, as there is no Make() for the function. */
+		ij.resolutionPath = append(ij.resolutionPath[:depth], derefType(functionType.In(i)).Name())
 
-			ij.log(
-				fmt.Sprintf(
-					"Retrieved delegate or factory result: %T, for delegate argument: %v, for delegate: %T",
-					delegateOrFactoryResult, arg, object,
-				),
-			)
+		inputs = append(inputs, ij.resolveInvocationArg(function, functionType, i))
+	}
 
-			results = append(results, reflect.ValueOf(delegateOrFactoryResult))
+	return inputs, nil
+}
 
+/* Finds the first unused override that can be assigned to the given argument type. */
+func findOverride(argType reflect.Type, overrides []interface{}, usedOverrides []bool) (reflect.Value, bool) {
+	for i, override := range overrides {
+		if usedOverrides[i] || override == nil {
 			continue
 		}
 
-		var newArg interface{}
+		if reflect.TypeOf(override).AssignableTo(argType) {
+			usedOverrides[i] = true
 
-		if arg.Kind() == reflect.Ptr {
-			newArg = reflect.New(arg.Elem()).Interface()
-		} else {
-			newArg = reflect.New(arg).Interface()
+			return reflect.ValueOf(override), true
 		}
+	}
+
+	return reflect.Value{}, false
+}
 
-		if objectType.In(i).Kind() == reflect.Struct && reflect.TypeOf(newArg).Kind() == reflect.Ptr {
-			newArg = reflect.ValueOf(newArg).Elem().Interface()
+/* Turns a trailing error return value into a Go error, returning the rest of the values as an array. */
+func splitErrorResult(functionType reflect.Type, results []reflect.Value) ([]interface{}, error) {
+	var err error
+
+	numOut := functionType.NumOut()
+
+	if numOut > 0 && functionType.Out(numOut-1) == errorType {
+		if errValue := results[numOut-1]; !errValue.IsNil() {
+			err = errValue.Interface().(error)
 		}
 
-		ij.log(fmt.Sprintf("Provisioning new argument: %T (as none cached) for delegate: %T", newArg, object))
+		results = results[:numOut-1]
+	}
 
-		results = append(results, reflect.ValueOf(ij.buildFields(newArg, newArg)))
+	outputs := make([]interface{}, len(results))
+
+	for i, result := range results {
+		outputs[i] = result.Interface()
 	}
 
-	return
+	return outputs, err
 }
 
 /*
 The only reason we would be calling this method is if there was not a factory delegated already, so this is for auto
 factory usage only.
 */
-func (ij *injector) getFactoryFromFactoryRegistry(name string) interface{} {
+func (ij *injector) getFactoryFromFactoryRegistry(name string) (interface{}, error) {
 	factoryTypes := ij.tr.FindFactoryTypes(name)
 
 	numFactories := len(factoryTypes)
 
 	if numFactories == 1 {
-		return factoryTypes[0]
+		return factoryTypes[0], nil
 	}
 
 	if numFactories > 1 {
-		ij.panic(
-			fmt.Sprintf(
-				"More than one factory exists in registry for object: '%s', you must Delegate() one first", name,
-			),
+		return nil, newFailure(
+			IssueMultipleFactories,
+			"More than one factory exists in registry for object: '%s', you must Delegate() one first", name,
 		)
 	}
 
-	return nil
+	return nil, nil
 }
 
 func (ij *injector) getValueAndNumFields(obj interface{}) (reflect.Value, int) {
@@ -781,8 +1423,6 @@ func (ij *injector) getValueAndNumFields(obj interface{}) (reflect.Value, int) {
 		val = val.Elem()
 	}
 
-	ij.log(fmt.Sprintf("Object: '%s' has %d field(s)", reflect.TypeOf(val.Interface()).String(), num))
-
 	return reflect.ValueOf(toStructPtr(val.Interface())).Elem(), num
 }
 
@@ -808,18 +1448,45 @@ func toStructPtr(obj interface{}) interface{} {
 	return vp.Interface()
 }
 
-/* Log normal 'debug-level' stuff. */
-func (ij *injector) log(msg string) {
-	if ij.logger != nil {
-		ij.logger.Debug(msg)
+/* Whether there is a logger and it logs the given level, so that messages and fields are only built when needed. */
+func (ij *injector) logEnabled(level Logger.Level) bool {
+	return ij.logger != nil && ij.logger.Enabled(level)
+}
+
+/*
+Logs at the level if the logger has it enabled. The fields are still built by the caller either way, so callers that
+build expensive fields check logEnabled() first.
+*/
+func (ij *injector) logAt(level Logger.Level, msg string, fields ...Logger.Field) {
+	if ij.logEnabled(level) {
+		/* Copied so that the logger can keep the fields after the call. */
+		ij.logger.Log(level, msg, append([]Logger.Field(nil), fields...)...)
 	}
 }
 
+/* Log every step of the resolution. */
+func (ij *injector) trace(msg string, fields ...Logger.Field) {
+	ij.logAt(Logger.LevelTrace, msg, fields...)
+}
+
+/* Log normal 'debug-level' stuff, like decisions taken. */
+func (ij *injector) debug(msg string, fields ...Logger.Field) {
+	ij.logAt(Logger.LevelDebug, msg, fields...)
+}
+
+/* Log what the user asked for. */
+func (ij *injector) info(msg string, fields ...Logger.Field) {
+	ij.logAt(Logger.LevelInfo, msg, fields...)
+}
+
+/* Log things that were ignored. */
+func (ij *injector) warn(msg string, fields ...Logger.Field) {
+	ij.logAt(Logger.LevelWarn, msg, fields...)
+}
+
 /* Log error stuff. */
-func (ij *injector) elog(msg string) {
-	if ij.logger != nil {
-		ij.logger.Error(msg)
-	}
+func (ij *injector) elog(msg string, fields ...Logger.Field) {
+	ij.logAt(Logger.LevelError, msg, fields...)
 }
 
 /* Log imminent death. And then die. */
@@ -828,3 +1495,10 @@ func (ij *injector) panic(msg string) {
 
 	panic(msg)
 }
+
+/* Log imminent death. And then die, with an error the user can inspect on recovery. */
+func (ij *injector) panicWithError(err error) {
+	ij.elog(err.Error())
+
+	panic(err)
+}
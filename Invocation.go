@@ -0,0 +1,159 @@
+package Goij
+
+import (
+	"fmt"
+	"reflect"
+)
+
+/* InvocationError describes why a method could not be invoked on an object with the given arguments. */
+type InvocationError struct {
+	/* The type of the object the method was invoked on, like "*main.IndexController". */
+	Type string
+
+	/* The name of the method that was invoked. */
+	Method string
+
+	/* The index of the offending parameter, or -1 when the problem is not with a single parameter. */
+	Index int
+
+	/* What went wrong. */
+	Reason string
+}
+
+func (e *InvocationError) Error() string {
+	if e.Index < 0 {
+		return fmt.Sprintf("Unable to invoke method: '%s' on type: '%s': %s", e.Method, e.Type, e.Reason)
+	}
+
+	return fmt.Sprintf(
+		"Unable to invoke method: '%s' on type: '%s', parameter %d: %s", e.Method, e.Type, e.Index, e.Reason,
+	)
+}
+
+/*
+Finds an exported method on the given object.
+
+Methods with pointer receivers are found on value objects too, by calling them on a pointer to a copy of the object.
+*/
+func findMethod(object interface{}, methodName string) (reflect.Value, error) {
+	if object == nil {
+		return reflect.Value{}, &InvocationError{Type: "nil", Method: methodName, Index: -1, Reason: "object is nil"}
+	}
+
+	value := reflect.ValueOf(object)
+
+	if method := value.MethodByName(methodName); method.IsValid() {
+		return method, nil
+	}
+
+	if value.Kind() != reflect.Ptr {
+		if method := reflect.ValueOf(toStructPtr(object)).MethodByName(methodName); method.IsValid() {
+			return method, nil
+		}
+	}
+
+	return reflect.Value{}, &InvocationError{
+		Type:   fmt.Sprintf("%T", object),
+		Method: methodName,
+		Index:  -1,
+		Reason: "no exported method exists with this name on either the value or pointer receiver",
+	}
+}
+
+/*
+Converts the user provided args into values suitable for calling a method of the given type.
+
+For variadic methods, the variadic args are packed into a slice ready for CallSlice(). Nil args are replaced with the
+zero value of the parameter when the parameter can hold nil, such as interfaces and pointers.
+*/
+func prepareInvocationArgs(
+	object interface{}, methodName string, methodType reflect.Type, args []interface{},
+) ([]reflect.Value, error) {
+	numIn := methodType.NumIn()
+	isVariadic := methodType.IsVariadic()
+
+	newError := func(index int, reason string) error {
+		return &InvocationError{Type: fmt.Sprintf("%T", object), Method: methodName, Index: index, Reason: reason}
+	}
+
+	if !isVariadic && len(args) != numIn {
+		return nil, newError(-1, fmt.Sprintf("expected %d argument(s), got %d", numIn, len(args)))
+	}
+
+	if isVariadic && len(args) < numIn-1 {
+		return nil, newError(-1, fmt.Sprintf("expected at least %d argument(s), got %d", numIn-1, len(args)))
+	}
+
+	inputs := make([]reflect.Value, 0, numIn)
+
+	numFixed := numIn
+
+	if isVariadic {
+		numFixed = numIn - 1
+	}
+
+	for i := 0; i < numFixed; i++ {
+		input, err := toArgValue(args[i], methodType.In(i))
+
+		if err != nil {
+			return nil, newError(i, err.Error())
+		}
+
+		inputs = append(inputs, input)
+	}
+
+	if !isVariadic {
+		return inputs, nil
+	}
+
+	sliceType := methodType.In(numIn - 1)
+
+	/* The user may have passed the variadic args as a single slice already. */
+	if len(args) == numIn && args[numIn-1] != nil && reflect.TypeOf(args[numIn-1]).AssignableTo(sliceType) {
+		return append(inputs, reflect.ValueOf(args[numIn-1])), nil
+	}
+
+	variadicArgs := reflect.MakeSlice(sliceType, 0, len(args)-numFixed)
+
+	for i := numFixed; i < len(args); i++ {
+		input, err := toArgValue(args[i], sliceType.Elem())
+
+		if err != nil {
+			return nil, newError(i, err.Error())
+		}
+
+		variadicArgs = reflect.Append(variadicArgs, input)
+	}
+
+	return append(inputs, variadicArgs), nil
+}
+
+/* Converts a single arg into a value assignable to the parameter type, dereferencing or referencing when needed. */
+func toArgValue(arg interface{}, paramType reflect.Type) (reflect.Value, error) {
+	if arg == nil {
+		switch paramType.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+			return reflect.Zero(paramType), nil
+		}
+
+		return reflect.Value{}, fmt.Errorf("nil cannot be used for a parameter of type: %s", paramType)
+	}
+
+	value := reflect.ValueOf(arg)
+
+	if value.Type().AssignableTo(paramType) {
+		return value, nil
+	}
+
+	/* A pointer was provided where a value is expected. */
+	if value.Kind() == reflect.Ptr && !value.IsNil() && value.Elem().Type().AssignableTo(paramType) {
+		return value.Elem(), nil
+	}
+
+	/* A value was provided where a pointer is expected. */
+	if paramType.Kind() == reflect.Ptr && value.Type().AssignableTo(paramType.Elem()) {
+		return reflect.ValueOf(toStructPtr(arg)), nil
+	}
+
+	return reflect.Value{}, fmt.Errorf("argument of type: %s is not assignable to type: %s", value.Type(), paramType)
+}
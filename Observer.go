@@ -0,0 +1,181 @@
+package Goij
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+/* EventKind is what happened in the resolution pipeline. */
+type EventKind string
+
+const (
+	/* Make() was asked for a name. */
+	EventResolveStart EventKind = "resolve start"
+
+	/* Make() returned, or panicked with the error in the event. It has the ID of the resolve start event. */
+	EventResolveEnd EventKind = "resolve end"
+
+	/* A shared or cached object was found for a type. */
+	EventCacheHit EventKind = "cache hit"
+
+	/* No shared or cached object was found for a type. */
+	EventCacheMiss EventKind = "cache miss"
+
+	/* A user-provided delegate was called. Events while resolving it's arguments are it's children. */
+	EventDelegateCalled EventKind = "delegate called"
+
+	/* An automatic factory from the registry was called. Events while resolving it's arguments are it's children. */
+	EventFactoryCalled EventKind = "factory called"
+
+	/* A struct was chosen for an interface, by a binding or as the single implementation. */
+	EventBindingApplied EventKind = "binding applied"
+
+	/* A definition, argument definition or environment variable was injected. */
+	EventDefinitionApplied EventKind = "definition applied"
+
+	/* Resolution failed; the injector is about to panic, or record the issue in diagnostics mode. */
+	EventError EventKind = "error"
+)
+
+/*
+Event is emitted to the functions registered with OnEvent() as the injector resolves dependencies.
+
+Events are children of the resolve, delegate or factory event in progress when they happen, so they form a tree that
+can be turned into traces. Resolve end, delegate and factory events are emitted when they finish, after their children,
+and have the time they took as their Duration.
+*/
+type Event struct {
+	/*
+		Never 0, and unique to each span within the injector: the resolve start and resolve end events of one Make() share
+		an ID, every other event has it's own.
+	*/
+	ID uint64
+
+	/* The ID of the event this happened within, or 0 for a Make() by the end user. */
+	ParentID uint64
+
+	Kind EventKind
+
+	/* The name given to Make(), or the interface a binding was applied for. */
+	Name string
+
+	/* The field or argument the event is for, if any. */
+	Field string
+
+	/* The type looked up in the cache, bound, defined, or returned from Make() or a delegate or factory. */
+	Type reflect.Type
+
+	/* Where a definition came from. */
+	Source Source
+
+	/* How an interface was bound. */
+	Binding Binding
+
+	/* The delegate or factory called. */
+	Function string
+
+	Duration time.Duration
+
+	/* Why resolution failed. */
+	Err error
+}
+
+/* An event in progress, which the events emitted before it ends are children of. */
+type eventSpan struct {
+	id       uint64
+	parentID uint64
+	started  time.Time
+}
+
+/*
+Registers a function that is called with each event from the resolution pipeline, for metrics and tracing.
+
+Functions are called in the order they were registered, synchronously, so should return quickly.
+*/
+func (ij *injector) OnEvent(observer func(Event)) {
+	ij.observers = append(ij.observers, observer)
+}
+
+/* Whether anything observes events, so that none are built otherwise. */
+func (ij *injector) observed() bool {
+	return len(ij.observers) > 0
+}
+
+/* Emits an event as a child of the event in progress. */
+func (ij *injector) emit(event Event) {
+	if !ij.observed() {
+		return
+	}
+
+	ij.lastEventID++
+
+	event.ID = ij.lastEventID
+	event.ParentID = ij.currentEventID()
+
+	ij.notify(event)
+}
+
+func (ij *injector) notify(event Event) {
+	for _, observer := range ij.observers {
+		observer(event)
+	}
+}
+
+func (ij *injector) currentEventID() uint64 {
+	if len(ij.eventSpans) == 0 {
+		return 0
+	}
+
+	return ij.eventSpans[len(ij.eventSpans)-1].id
+}
+
+/* Starts an event that the events emitted until it is ended are children of. */
+func (ij *injector) startSpan() *eventSpan {
+	ij.lastEventID++
+
+	span := &eventSpan{id: ij.lastEventID, parentID: ij.currentEventID(), started: time.Now()}
+
+	ij.eventSpans = append(ij.eventSpans, span)
+
+	return span
+}
+
+/* Ends the event in progress, emitting it with the time it took. The problem is any value recovered from a panic. */
+func (ij *injector) endSpan(span *eventSpan, event Event, problem interface{}) {
+	ij.eventSpans = ij.eventSpans[:len(ij.eventSpans)-1]
+
+	event.ID = span.id
+	event.ParentID = span.parentID
+	event.Duration = time.Since(span.started)
+
+	if problem != nil {
+		event.Err = problemError(problem)
+	}
+
+	ij.notify(event)
+}
+
+/* Emits a cache hit or miss for a type, and the field or argument it was looked up for. */
+func (ij *injector) emitCacheLookup(found bool, field string, objType reflect.Type) {
+	if !ij.observed() {
+		return
+	}
+
+	kind := EventCacheMiss
+
+	if found {
+		kind = EventCacheHit
+	}
+
+	ij.emit(Event{Kind: kind, Field: field, Type: objType})
+}
+
+/* The error for a value recovered from a panic, which are usually the message of a failure. */
+func problemError(problem interface{}) error {
+	if err, isErr := problem.(error); isErr {
+		return err
+	}
+
+	return fmt.Errorf("%v", problem)
+}
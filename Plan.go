@@ -0,0 +1,186 @@
+package Goij
+
+import (
+	"fmt"
+	"strings"
+)
+
+/* StepAction is what a step in a construction plan does. */
+type StepAction string
+
+const (
+	/* A struct is initialised before it's fields are set. */
+	StepInitialise StepAction = "initialise"
+
+	/* A delegate or factory is called with it's arguments. */
+	StepCall StepAction = "call"
+
+	/* A field is set from a definition, shared object, delegate, factory or initialised struct. */
+	StepSetField StepAction = "set"
+
+	/* The object made is cached, and shared for any future injection. */
+	StepCache StepAction = "cache"
+)
+
+/* PlanStep is a single step that Make() would take. */
+type PlanStep struct {
+	Action StepAction
+
+	/* The path of the field or argument the step is for, like "IndexController.Users.DB". */
+	Path string
+
+	/* The type initialised, returned from the function called, or cached. */
+	Type string
+
+	/* Where the value comes from. */
+	Source Source
+
+	/* The delegate or factory called. */
+	Function string
+
+	/* Where each argument of the delegate or factory comes from, like "dsn: definition string". */
+	Arguments []string
+
+	/* Where the value of the field comes from, like "registry Repository.MySQLUsers (bind of Repository.Users)". */
+	From string
+}
+
+func (s *PlanStep) String() string {
+	switch s.Action {
+	case StepInitialise:
+		return fmt.Sprintf("%s %s for %s", s.Action, s.Type, s.Path)
+	case StepCall:
+		return fmt.Sprintf("%s %s %s(%s) for %s", s.Action, s.Source, s.Function, strings.Join(s.Arguments, ", "), s.Path)
+	case StepSetField:
+		return fmt.Sprintf("%s %s from %s", s.Action, s.Path, s.From)
+	}
+
+	return fmt.Sprintf("%s %s", s.Action, s.Type)
+}
+
+/* Plan is the ordered list of steps Make() would take for a name, returned from Plan(). */
+type Plan struct {
+	Root  string
+	Steps []*PlanStep
+}
+
+func (ij *injector) Plan(name string) (*Plan, error) {
+	resolution, err := ij.Explain(name)
+
+	plan := &Plan{Root: name}
+	plan.add(resolution, false)
+
+	/* Make() caches what it initialises, but not what delegates and factories return as it may be dynamic. */
+	if resolution.Source == SourceRegistry && resolution.Problem == nil {
+		plan.Steps = append(
+			plan.Steps, &PlanStep{Action: StepCache, Path: resolution.Path, Type: resolution.Provided.String()},
+		)
+	}
+
+	return plan, err
+}
+
+/* Adds the steps to provide a resolution, after the steps for it's dependencies, then the step to set it as a field. */
+func (p *Plan) add(r *Resolution, isField bool) {
+	if r.Problem != nil && r.Source == "" {
+		return
+	}
+
+	switch r.Source {
+	case SourceDelegate, SourceFactory:
+		var arguments []string
+
+		for _, argument := range r.Dependencies {
+			p.add(argument, false)
+
+			arguments = append(arguments, fmt.Sprintf("%s: %s", argument.Name, argument.describe()))
+		}
+
+		p.Steps = append(p.Steps, &PlanStep{
+			Action:    StepCall,
+			Path:      r.Path,
+			Type:      fmt.Sprint(r.Provided),
+			Source:    r.Source,
+			Function:  r.Function,
+			Arguments: arguments,
+		})
+	case SourceRegistry, SourceNew:
+		p.Steps = append(
+			p.Steps, &PlanStep{Action: StepInitialise, Path: r.Path, Type: r.Provided.String(), Source: r.Source},
+		)
+
+		for _, field := range r.Dependencies {
+			p.add(field, true)
+		}
+	}
+
+	/* Zero values are left as they are. */
+	if isField && r.Source != SourceZeroValue {
+		p.Steps = append(p.Steps, &PlanStep{Action: StepSetField, Path: r.Path, Source: r.Source, From: r.describe()})
+	}
+}
+
+/* String lists the steps of the plan, one per line. */
+func (p *Plan) String() string {
+	return strings.Join(p.lines(), "\n")
+}
+
+func (p *Plan) lines() []string {
+	lines := make([]string, len(p.Steps))
+
+	for i, step := range p.Steps {
+		lines[i] = step.String()
+	}
+
+	return lines
+}
+
+/*
+Compare lists the differences from this plan to another, like one made by an injector with a different configuration.
+
+Steps only in this plan are prefixed with "- " and steps only in the other plan with "+ ", in the order they would be
+taken. Nil is returned when the plans are the same.
+*/
+func (p *Plan) Compare(other *Plan) []string {
+	before := p.lines()
+	after := other.lines()
+
+	/* The longest common subsequence of steps, from the end of both plans. */
+	common := make([][]int, len(before)+1)
+
+	for i := range common {
+		common[i] = make([]int, len(after)+1)
+	}
+
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else if common[i+1][j] >= common[i][j+1] {
+				common[i][j] = common[i+1][j]
+			} else {
+				common[i][j] = common[i][j+1]
+			}
+		}
+	}
+
+	var differences []string
+
+	i, j := 0, 0
+
+	for i < len(before) || j < len(after) {
+		switch {
+		case i < len(before) && j < len(after) && before[i] == after[j]:
+			i++
+			j++
+		case j == len(after) || (i < len(before) && common[i+1][j] >= common[i][j+1]):
+			differences = append(differences, "- "+before[i])
+			i++
+		default:
+			differences = append(differences, "+ "+after[j])
+			j++
+		}
+	}
+
+	return differences
+}
@@ -0,0 +1,568 @@
+package Goij
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+/* Source is where the value for a type, field or argument comes from. */
+type Source string
+
+const (
+	/* Define() for the struct, or for the delegate or factory. */
+	SourceDefinition Source = "definition"
+
+	/* DefineNamedType() for the type and name of the field or argument. */
+	SourceNamedTypeDefinition Source = "named type definition"
+
+	/* DefineGlobal() for the name of the field or argument. */
+	SourceGlobalDefinition Source = "global definition"
+
+	/* DefineType() for the type of the field or argument. */
+	SourceTypeDefinition Source = "type definition"
+
+	/* DefineArg() for the position of the argument. */
+	SourceArgDefinition Source = "argument definition"
+
+	/* The environment variable in the `env` tag of the field, or it's default. */
+	SourceEnvironment Source = "environment"
+
+	/* Scalars without any definition are left as their zero value. */
+	SourceZeroValue Source = "zero value"
+
+	/* An object given to Share(), or already made. */
+	SourceShared Source = "shared"
+
+	/* A user delegate given to Delegate(). */
+	SourceDelegate Source = "delegate"
+
+	/* An automatic factory, like NewServer(), from the type registry. */
+	SourceFactory Source = "factory"
+
+	/* A struct from the type registry, with it's own fields resolved. */
+	SourceRegistry Source = "registry"
+
+	/* A delegate or factory argument that is not in the type registry, initialised with it's fields resolved. */
+	SourceNew Source = "new"
+)
+
+/* Binding is how an interface was resolved to a struct. */
+type Binding string
+
+const (
+	/* Bind() with the full name of the interface. */
+	BindingFullName Binding = "bind"
+
+	/* Bind() with the short name of the interface, without the package. */
+	BindingShortName Binding = "short-name bind"
+
+	/* The only struct in the type registry implementing the interface. */
+	BindingSingleImplementation Binding = "single implementation"
+)
+
+/*
+Resolution is a decision made when resolving a type, field or argument, and the decisions made for it's dependencies:
+the fields of a struct or the arguments of a delegate or factory. It is returned from Explain().
+
+Resolutions are made with the same lookups as Make(), but nothing is initialised and no delegate or factory is called.
+*/
+type Resolution struct {
+	/* The name of the field or argument, or the name given for the root. */
+	Name string
+
+	/* The path of fields and arguments from the root, like "IndexController.Users.DB". */
+	Path string
+
+	/* The type of the field or argument, nil for a name that could not be found. */
+	Requested reflect.Type
+
+	/* The type that will be injected, if it is known without calling anything. */
+	Provided reflect.Type
+
+	/* Where the value comes from. */
+	Source Source
+
+	/* How an interface was resolved to a struct, if it was. */
+	Binding Binding
+
+	/* The name of the delegate or factory function providing the value. */
+	Function string
+
+	/* What else could have provided the value, and why it did not. */
+	Alternatives []string
+
+	/* The resolutions of the fields of a struct, or the arguments of a delegate or factory. */
+	Dependencies []*Resolution
+
+	/* Why this could not be resolved, nil if it could. */
+	Problem error
+
+	/* The delegate or factory providing the value. */
+	delegate *delegateLookup
+
+	/* The definition, or raw environment variable, providing the value. */
+	value interface{}
+}
+
+/* Resolves the dependency graph for a name given to Make(), without initialising anything. */
+type analysis struct {
+	ij *injector
+
+	/* The structs and functions on the current path, so circular dependencies are found rather than followed. */
+	visiting map[string]bool
+}
+
+/* Analyses the dependency graph of a struct or interface name, the same way Make() would resolve it. */
+func (ij *injector) analyse(name string) *Resolution {
+	a := &analysis{ij: ij, visiting: make(map[string]bool)}
+
+	node := &Resolution{Name: name, Path: name[strings.LastIndex(name, ".")+1:]}
+
+	resolved, err := ij.resolveTypeName(name)
+
+	if err != nil {
+		node.Problem = err
+
+		return node
+	}
+
+	if interfaceType := ij.tr.FindInterfaceType(name); interfaceType != nil {
+		node.Requested = delegateLookupType(interfaceType)
+	}
+
+	if resolved.delegate != nil {
+		node.addImplementationAlternatives(resolved)
+		a.delegate(node, resolved.delegate)
+
+		return node
+	}
+
+	if node.Requested == nil {
+		node.Requested = reflect.TypeOf(resolved.structType)
+	}
+
+	node.Binding = resolved.binding
+	node.addImplementationAlternatives(resolved)
+
+	a.structType(node, reflect.TypeOf(resolved.structType), nil)
+
+	return node
+}
+
+/*
+Resolves a struct in the same order as Make() and the fields of a struct: a shared object, a delegate or factory for
+the struct or, for interfaces, the interface, and then the struct from the registry with each of it's fields resolved.
+
+Structs that are not in the registry are resolved as new, which is only allowed for delegate and factory arguments.
+*/
+func (a *analysis) structType(node *Resolution, structType reflect.Type, interfaceType reflect.Type) {
+	node.Provided = derefType(structType)
+
+	if a.ij.objectCache.FindByType(structType) != nil {
+		node.Source = SourceShared
+
+		if lookup, _ := a.ij.findDelegateOrFactory(structType); lookup != nil {
+			node.addAlternative("%s, as the shared object is used first", lookup)
+		}
+
+		return
+	}
+
+	lookupTypes := []reflect.Type{structType}
+
+	if interfaceType != nil {
+		lookupTypes = append(lookupTypes, interfaceType)
+	}
+
+	for _, lookupType := range lookupTypes {
+		lookup, err := a.ij.findDelegateOrFactory(lookupType)
+
+		if err != nil {
+			node.Problem = err
+
+			return
+		}
+
+		if lookup != nil {
+			a.delegate(node, lookup)
+
+			if a.ij.tr.FindStructTypeByType(structType) != nil {
+				node.addAlternative("registry struct: %s, as the %s is used first", derefType(structType), lookup)
+			}
+
+			return
+		}
+	}
+
+	node.Source = SourceRegistry
+
+	if a.ij.tr.FindStructTypeByType(structType) == nil {
+		node.Source = SourceNew
+	}
+
+	a.fields(node, node.Provided)
+}
+
+/* Resolves each exported field of a struct. */
+func (a *analysis) fields(node *Resolution, structType reflect.Type) {
+	key := structType.PkgPath() + "." + structType.Name()
+
+	if a.visiting[key] {
+		node.Problem = newFailure(IssueCircularDependency, "Circular dependency on type: '%s'", structType)
+
+		return
+	}
+
+	a.visiting[key] = true
+	defer delete(a.visiting, key)
+
+	for i := 0; i < structType.NumField(); i++ {
+		/* Ignore private fields */
+		if structType.Field(i).PkgPath != "" {
+			continue
+		}
+
+		node.Dependencies = append(node.Dependencies, a.field(node, structType, structType.Field(i)))
+	}
+}
+
+/* Resolves a field the same way as buildFields(). */
+func (a *analysis) field(parent *Resolution, structType reflect.Type, field reflect.StructField) *Resolution {
+	fieldType := field.Type
+
+	node := &Resolution{Name: field.Name, Path: parent.Path + "." + field.Name, Requested: fieldType}
+
+	definition, definitionSource := a.ij.findDefinitionOrGlobalDefinition(structType, field.Name, fieldType)
+
+	node.addDefinitionAlternatives(a.ij.findDefinitionCandidates(structType, field.Name, fieldType))
+
+	if definition != nil {
+		if _, err := a.ij.converter.Convert(definition, fieldType); err != nil {
+			node.Problem = newFailure(
+				IssueDefinitionMismatch,
+				"Definition for field: '%s' on object: %s could not be injected: %s", field.Name, structType, err.Error(),
+			)
+		}
+
+		node.Source = definitionSource
+		node.Provided = reflect.TypeOf(definition)
+		node.value = definition
+
+		return node
+	}
+
+	/* Interfaces */
+	if fieldType.Kind() == reflect.Interface {
+		a.interfaceType(node, fieldType, field.Name)
+
+		return node
+	}
+
+	/* Scalars */
+	if derefType(fieldType).Kind() != reflect.Struct {
+		node.Source = SourceZeroValue
+		node.Provided = fieldType
+
+		envName, raw, found, missing := lookupEnvTag(field)
+
+		if missing {
+			node.Problem = newFailure(
+				IssueMissingEnvironment,
+				"Environment variable: '%s' is required for field: '%s' but is not set", envName, field.Name,
+			)
+		}
+
+		if !found {
+			return node
+		}
+
+		node.Source = SourceEnvironment
+		node.value = raw
+
+		if _, err := a.ij.converter.Convert(raw, fieldType); err != nil {
+			node.Problem = newFailure(
+				IssueDefinitionMismatch,
+				"Environment variable: '%s' could not be used for field: '%s', error: %s", envName, field.Name, err.Error(),
+			)
+		}
+
+		return node
+	}
+
+	a.structType(node, fieldType, nil)
+
+	if node.Source == SourceNew {
+		node.Problem = newFailure(
+			IssueMissingType, "No type found in registry for name: '%s', did you forget to register it?", field.Name,
+		)
+		node.Dependencies = nil
+	}
+
+	return node
+}
+
+/* Resolves an interface field to a struct, or to a delegate or factory for the interface. */
+func (a *analysis) interfaceType(node *Resolution, interfaceType reflect.Type, name string) {
+	resolved, err := a.ij.resolveInterface(interfaceType, name)
+
+	if err != nil {
+		node.Problem = err
+
+		return
+	}
+
+	node.addImplementationAlternatives(resolved)
+
+	if resolved.delegate != nil {
+		a.delegate(node, resolved.delegate)
+
+		return
+	}
+
+	node.Binding = resolved.binding
+
+	a.structType(node, reflect.TypeOf(resolved.structType), interfaceType)
+}
+
+/* Resolves the arguments of a delegate or factory, which is not called. */
+func (a *analysis) delegate(node *Resolution, lookup *delegateLookup) {
+	node.Source = SourceDelegate
+	node.Function = lookup.functionName()
+	node.delegate = lookup
+
+	if lookup.isFactory {
+		node.Source = SourceFactory
+	}
+
+	function := lookup.function
+	functionType := getElem(function).Type()
+
+	if functionType.NumOut() > 0 {
+		node.Provided = functionType.Out(0)
+	}
+
+	key := "func " + lookup.name
+
+	if a.visiting[key] {
+		node.Problem = newFailure(IssueCircularDependency, "Circular dependency on %s for: '%s'", node.Source, lookup.name)
+
+		return
+	}
+
+	a.visiting[key] = true
+	defer delete(a.visiting, key)
+
+	for i := 0; i < functionType.NumIn(); i++ {
+		node.Dependencies = append(node.Dependencies, a.argument(node, function, functionType, i))
+	}
+}
+
+/* Resolves an argument of a delegate or factory the same way as resolveInvocationArg(). */
+func (a *analysis) argument(
+	parent *Resolution, function interface{}, functionType reflect.Type, position int,
+) *Resolution {
+	arg := functionType.In(position)
+
+	name := a.ij.argPathName(function, position)
+
+	node := &Resolution{Name: name, Path: parent.Path + "." + name, Requested: arg}
+
+	/* Has the user defined the argument at this position with DefineArg(), or by it's type or name? */
+	definition, found := a.ij.findArgDefinition(function, functionType, position)
+	definitionSource := SourceArgDefinition
+
+	if !found {
+		definition, definitionSource, found = a.ij.findNamedArgDefinition(function, position)
+	}
+
+	if found {
+		if _, err := a.ij.converter.Convert(definition, arg); err != nil {
+			node.Problem = newFailure(
+				IssueDefinitionMismatch,
+				"Definition for argument %d of function: %s could not be injected: %s", position, functionType, err.Error(),
+			)
+		}
+
+		node.Source = definitionSource
+		node.Provided = reflect.TypeOf(definition)
+		node.value = definition
+
+		return node
+	}
+
+	/* Without a definition scalars are the zero value. */
+	if derefType(arg).Kind() != reflect.Interface && derefType(arg).Kind() != reflect.Struct {
+		node.Source = SourceZeroValue
+		node.Provided = arg
+
+		return node
+	}
+
+	if arg.Kind() == reflect.Interface {
+		lookup, err := a.ij.findDelegateOrFactory(arg)
+
+		if err != nil {
+			node.Problem = err
+
+			return node
+		}
+
+		if lookup != nil {
+			a.delegate(node, lookup)
+
+			return node
+		}
+
+		resolved, err := a.ij.resolveInterface(arg, fmt.Sprintf("%s.%s", arg.PkgPath(), arg.Name()))
+
+		if err != nil {
+			node.Problem = err
+
+			return node
+		}
+
+		node.addImplementationAlternatives(resolved)
+
+		if resolved.delegate != nil {
+			a.delegate(node, resolved.delegate)
+
+			return node
+		}
+
+		node.Binding = resolved.binding
+		arg = derefType(reflect.TypeOf(resolved.structType))
+
+		/* The struct is passed uninitialised when the function returns it, to avoid infinite recursion. */
+		if functionType.NumOut() > 0 && strings.ToLower(arg.String()) == strings.ToLower(functionType.Out(0).String()) {
+			node.Source = SourceRegistry
+			node.Provided = arg
+
+			return node
+		}
+	}
+
+	/* Arguments not in the registry are still initialised, rather than being an error like fields. */
+	a.structType(node, arg, nil)
+
+	return node
+}
+
+/* Describes something else that could have provided the value, and why it did not. */
+func (r *Resolution) addAlternative(format string, args ...interface{}) {
+	r.Alternatives = append(r.Alternatives, fmt.Sprintf(format, args...))
+}
+
+/* Describes the definitions matching a field that are not used, as they are less specific or can't be converted. */
+func (r *Resolution) addDefinitionAlternatives(candidates []definitionCandidate) {
+	used := false
+
+	for _, candidate := range candidates {
+		switch {
+		case !candidate.usable:
+			r.addAlternative(
+				"%s of type: %T, as it can't be converted to: %s", candidate.source, candidate.value, r.Requested,
+			)
+		case used:
+			r.addAlternative("%s of type: %T, as it is less specific", candidate.source, candidate.value)
+		default:
+			used = true
+		}
+	}
+}
+
+/* Describes the structs implementing an interface that are not used for it. */
+func (r *Resolution) addImplementationAlternatives(resolved *typeLookup) {
+	for _, candidate := range resolved.candidates {
+		if resolved.structType != nil && reflect.TypeOf(candidate) == reflect.TypeOf(resolved.structType) {
+			continue
+		}
+
+		if resolved.delegate != nil {
+			r.addAlternative("implementation: %s, as the interface has a %s", reflect.TypeOf(candidate), resolved.delegate)
+
+			continue
+		}
+
+		r.addAlternative("implementation: %s, as the interface is bound with %s", reflect.TypeOf(candidate), resolved.binding)
+	}
+}
+
+/* The full name of the delegate or factory function, or the type it was found by if that is unavailable. */
+func (d *delegateLookup) functionName() string {
+	if names := functionNames(d.function); len(names) > 0 {
+		return names[0]
+	}
+
+	return d.name
+}
+
+/* Describes the delegate or factory, like "factory: github.com/x/pkg.NewServer". */
+func (d *delegateLookup) String() string {
+	if d.isFactory {
+		return fmt.Sprintf("%s: %s", SourceFactory, d.functionName())
+	}
+
+	return fmt.Sprintf("%s: %s", SourceDelegate, d.functionName())
+}
+
+func (ij *injector) Explain(name string) (*Resolution, error) {
+	resolution := ij.analyse(name)
+
+	if issues := resolution.issues(name); len(issues) > 0 {
+		return resolution, &ResolutionError{Issues: issues}
+	}
+
+	return resolution, nil
+}
+
+/* String describes the resolution and it's dependencies as an indented tree, with the alternatives considered. */
+func (r *Resolution) String() string {
+	var lines []string
+
+	r.walkDepth(0, func(node *Resolution, depth int) {
+		indent := strings.Repeat("  ", depth)
+
+		lines = append(lines, fmt.Sprintf("%s%s: %s", indent, node.Name, node.describe()))
+
+		for _, alternative := range node.Alternatives {
+			lines = append(lines, fmt.Sprintf("%s  - not used: %s", indent, alternative))
+		}
+	})
+
+	return strings.Join(lines, "\n")
+}
+
+/* Describes the decision, like "registry test.testObj (single implementation of test.testInterface)". */
+func (r *Resolution) describe() string {
+	if r.Problem != nil && r.Source == "" {
+		return "unresolved: " + r.Problem.Error()
+	}
+
+	description := string(r.Source)
+
+	if r.Function != "" {
+		description += " " + r.Function
+	} else if r.Provided != nil && r.Source != SourceZeroValue {
+		description += " " + r.Provided.String()
+	}
+
+	if r.Requested != nil && r.Requested.Kind() == reflect.Interface && r.Binding != "" {
+		description += fmt.Sprintf(" (%s of %s)", r.Binding, r.Requested)
+	}
+
+	if r.Problem != nil {
+		description += ", unresolved: " + r.Problem.Error()
+	}
+
+	return description
+}
+
+/* Calls the function for this resolution and each of it's dependencies, depth first, with their depth. */
+func (r *Resolution) walkDepth(depth int, function func(node *Resolution, depth int)) {
+	function(r, depth)
+
+	for _, dependency := range r.Dependencies {
+		dependency.walkDepth(depth+1, function)
+	}
+}
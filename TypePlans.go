@@ -0,0 +1,178 @@
+package Goij
+
+import (
+	"reflect"
+)
+
+/*
+The decisions for resolving each type that only change with the configuration or the registry, compiled the first time
+they are needed so that each Make() doesn't repeat the reflection and registry searches for them.
+*/
+type typePlans struct {
+	/* The version of the registry the plans were compiled against. */
+	registryVersion uint64
+
+	structs map[reflect.Type]*structPlan
+
+	/* Lookups by the name given to Make(), by interface type and by the type delegates are found for. */
+	names      map[string]*typeLookup
+	interfaces map[reflect.Type]*typeLookup
+
+	/* Structs in the registry by their type, nil when a type isn't registered. */
+	registryStructs map[reflect.Type]interface{}
+
+	/* Nil when a type has neither a delegate nor a factory. */
+	delegates map[reflect.Type]*delegateLookup
+}
+
+/* The fields of a struct type, and the definitions found for them. */
+type structPlan struct {
+	structType reflect.Type
+	fields     []fieldPlan
+}
+
+type fieldPlan struct {
+	index     int
+	name      string
+	fieldType reflect.Type
+	isPointer bool
+
+	/* Private fields are ignored. */
+	exported bool
+
+	/* The definition found for the field, if any. */
+	definition       interface{}
+	definitionSource Source
+}
+
+func newTypePlans(registryVersion uint64) *typePlans {
+	return &typePlans{
+		registryVersion: registryVersion,
+		structs:         make(map[reflect.Type]*structPlan),
+		names:           make(map[string]*typeLookup),
+		interfaces:      make(map[reflect.Type]*typeLookup),
+		registryStructs: make(map[reflect.Type]interface{}),
+		delegates:       make(map[reflect.Type]*delegateLookup),
+	}
+}
+
+/* Throws away the compiled plans, as a binding, definition, delegate or converter has changed. */
+func (ij *injector) invalidatePlans() {
+	ij.plans = nil
+}
+
+/* The compiled plans, which are thrown away first if the registry has had types added to it. */
+func (ij *injector) compiledPlans() *typePlans {
+	if ij.plans == nil || ij.plans.registryVersion != ij.tr.Version() {
+		ij.plans = newTypePlans(ij.tr.Version())
+	}
+
+	return ij.plans
+}
+
+/* The plan for the fields of a struct type, compiled on first use. */
+func (ij *injector) structPlan(structType reflect.Type) *structPlan {
+	plans := ij.compiledPlans()
+
+	if plan, found := plans.structs[structType]; found {
+		return plan
+	}
+
+	plan := &structPlan{structType: structType, fields: make([]fieldPlan, structType.NumField())}
+
+	for i := range plan.fields {
+		field := structType.Field(i)
+
+		plan.fields[i] = fieldPlan{
+			index:     i,
+			name:      field.Name,
+			fieldType: field.Type,
+			isPointer: field.Type.Kind() == reflect.Ptr,
+			exported:  field.PkgPath == "",
+		}
+
+		if plan.fields[i].exported {
+			plan.fields[i].definition, plan.fields[i].definitionSource = ij.findDefinitionOrGlobalDefinition(
+				structType, field.Name, field.Type,
+			)
+		}
+	}
+
+	plans.structs[structType] = plan
+
+	return plan
+}
+
+/* Resolves a name given to Make(), compiling it on first use. Failures are not kept, so are reported every time. */
+func (ij *injector) resolveTypeName(name string) (*typeLookup, error) {
+	plans := ij.compiledPlans()
+
+	if lookup, found := plans.names[name]; found {
+		return lookup, nil
+	}
+
+	lookup, err := ij.compileTypeName(name)
+
+	if err == nil {
+		plans.names[name] = lookup
+	}
+
+	return lookup, err
+}
+
+/* Resolves the struct or delegate for an interface type, compiling it on first use. */
+func (ij *injector) resolveInterface(fieldType reflect.Type, fieldName string) (*typeLookup, error) {
+	plans := ij.compiledPlans()
+
+	if lookup, found := plans.interfaces[fieldType]; found {
+		return lookup, nil
+	}
+
+	lookup, err := ij.compileInterface(fieldType, fieldName)
+
+	if err == nil {
+		plans.interfaces[fieldType] = lookup
+	}
+
+	return lookup, err
+}
+
+/* Finds the delegate or factory for a type, compiling it on first use. */
+func (ij *injector) findDelegateOrFactory(objType interface{}) (*delegateLookup, error) {
+	lookupType := delegateLookupType(objType)
+	plans := ij.compiledPlans()
+
+	if lookup, found := plans.delegates[lookupType]; found {
+		return lookup, nil
+	}
+
+	lookup, err := ij.compileDelegateOrFactory(lookupType)
+
+	if err == nil {
+		plans.delegates[lookupType] = lookup
+	}
+
+	return lookup, err
+}
+
+/* Finds a copy of the struct in the registry for a type, or the type a pointer points to, as a pointer to it. */
+func (ij *injector) findStructTypeByType(objType reflect.Type) interface{} {
+	plans := ij.compiledPlans()
+	objType = derefType(objType)
+
+	structType, found := plans.registryStructs[objType]
+
+	if !found {
+		if structPtr := ij.tr.FindStructTypeByType(objType); structPtr != nil {
+			structType = getValue(structPtr)
+		}
+
+		plans.registryStructs[objType] = structType
+	}
+
+	if structType == nil {
+		return nil
+	}
+
+	return toStructPtr(structType)
+}
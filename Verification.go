@@ -0,0 +1,180 @@
+package Goij
+
+import (
+	"fmt"
+	"github.com/j7mbo/goij/src/Logger"
+	"strings"
+)
+
+/* IssueKind is the kind of problem found when resolving a type. */
+type IssueKind string
+
+const (
+	/* A struct or interface is not in the type registry. */
+	IssueMissingType IssueKind = "missing type"
+
+	/* An interface has no implementing struct in the registry, no binding and no delegate. */
+	IssueMissingImplementation IssueKind = "missing implementation"
+
+	/* An interface has multiple implementing structs in the registry and none has been bound. */
+	IssueAmbiguousInterface IssueKind = "ambiguous interface"
+
+	/* A struct has multiple automatic factories in the registry and no delegate. */
+	IssueMultipleFactories IssueKind = "multiple factories"
+
+	/* A definition or environment variable cannot be converted to the type of it's field or argument. */
+	IssueDefinitionMismatch IssueKind = "definition type mismatch"
+
+	/* A required environment variable is not set. */
+	IssueMissingEnvironment IssueKind = "missing environment variable"
+
+	/* A struct depends on itself, or a delegate or factory on the type it returns. */
+	IssueCircularDependency IssueKind = "circular dependency"
+
+	/* A delegate or factory returned an error as it's last result. */
+	IssueFactoryError IssueKind = "factory error"
+
+	/* A delegate, factory or anything else panicked while making a type in diagnostics mode. */
+	IssuePanic IssueKind = "panic"
+)
+
+/* ResolutionIssue is a problem found when resolving a type, with the path of fields and arguments leading to it. */
+type ResolutionIssue struct {
+	/* The struct or interface name being resolved, as given to Make() or Verify(). */
+	Root string
+
+	/* The path of fields and arguments from the root to the problem, like "IndexController.Users.DB". */
+	Path string
+
+	/* The kind of problem. */
+	Kind IssueKind
+
+	/* Why the field, argument or type could not be resolved. */
+	Reason string
+
+	/* The error returned by a delegate or factory, if that is why. */
+	cause error
+}
+
+func (e *ResolutionIssue) Error() string {
+	return fmt.Sprintf("Unable to resolve: '%s' for: '%s': %s", e.Path, e.Root, e.Reason)
+}
+
+/* Unwrap returns the error returned by a delegate or factory, so that it can be checked with errors.Is() and As(). */
+func (e *ResolutionIssue) Unwrap() error {
+	return e.cause
+}
+
+/* ResolutionError contains every issue found when resolving one or more types, and lists them grouped by root. */
+type ResolutionError struct {
+	Issues []*ResolutionIssue
+}
+
+/* NewResolutionError aggregates the errors returned from Verify() into a single error, or returns nil if none. */
+func NewResolutionError(errs []error) error {
+	resolutionError := &ResolutionError{}
+
+	for _, err := range errs {
+		switch typedErr := err.(type) {
+		case *ResolutionIssue:
+			resolutionError.Issues = append(resolutionError.Issues, typedErr)
+		case *ResolutionError:
+			resolutionError.Issues = append(resolutionError.Issues, typedErr.Issues...)
+		case nil:
+		default:
+			resolutionError.Issues = append(
+				resolutionError.Issues, &ResolutionIssue{Kind: IssuePanic, Reason: typedErr.Error()},
+			)
+		}
+	}
+
+	if len(resolutionError.Issues) == 0 {
+		return nil
+	}
+
+	return resolutionError
+}
+
+func (e *ResolutionError) Error() string {
+	var roots []string
+
+	issuesByRoot := make(map[string][]*ResolutionIssue)
+
+	for _, issue := range e.Issues {
+		if _, found := issuesByRoot[issue.Root]; !found {
+			roots = append(roots, issue.Root)
+		}
+
+		issuesByRoot[issue.Root] = append(issuesByRoot[issue.Root], issue)
+	}
+
+	lines := []string{fmt.Sprintf("Unable to resolve %d issue(s) for %d type(s):", len(e.Issues), len(roots))}
+
+	for _, root := range roots {
+		lines = append(lines, fmt.Sprintf("'%s':", root))
+
+		for _, issue := range issuesByRoot[root] {
+			lines = append(lines, fmt.Sprintf("  - %s (%s): %s", issue.Path, issue.Kind, issue.Reason))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+/* A problem found by one of the lookups shared by Make() and Verify(), which is given a path when it is recorded. */
+type resolutionFailure struct {
+	kind    IssueKind
+	message string
+
+	/* The error returned by a delegate or factory. */
+	err error
+}
+
+func (e *resolutionFailure) Error() string {
+	return e.message
+}
+
+func newFailure(kind IssueKind, format string, args ...interface{}) error {
+	return &resolutionFailure{kind: kind, message: fmt.Sprintf(format, args...)}
+}
+
+/* Builds an issue for a problem found at the given path, keeping the kind of the failure if it has one. */
+func newIssue(root string, path string, problem interface{}) *ResolutionIssue {
+	issue := &ResolutionIssue{Root: root, Path: path, Kind: IssuePanic, Reason: fmt.Sprint(problem)}
+
+	if failure, isFailure := problem.(*resolutionFailure); isFailure {
+		issue.Kind = failure.kind
+		issue.cause = failure.err
+	}
+
+	return issue
+}
+
+func (ij *injector) Verify(names ...string) []error {
+	if len(names) == 0 {
+		names = sortedKeys(ij.tr.FindAllStructTypes())
+	}
+
+	var errs []error
+
+	for _, name := range names {
+		ij.info("Verifying the dependency graph", Logger.Any("name", name))
+
+		for _, issue := range ij.analyse(name).issues(name) {
+			errs = append(errs, issue)
+		}
+	}
+
+	return errs
+}
+
+/* Every problem found in this resolution and it's dependencies. */
+func (r *Resolution) issues(root string) (issues []*ResolutionIssue) {
+	r.walkDepth(0, func(node *Resolution, depth int) {
+		if node.Problem != nil {
+			issues = append(issues, newIssue(root, node.Path, node.Problem))
+		}
+	})
+
+	return issues
+}
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"github.com/j7mbo/goij/src/TypeRegistry"
+	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 /* Provides the ability to handle multiple parameters for a single flag. */
@@ -14,6 +18,34 @@ type arrayFlags []string
 /* Allows user to pass -exclude path1 -exclude path2 */
 var excludeFlags arrayFlags
 
+/* Allows user to pass -root pkg.TypeOne -root pkg.TypeTwo */
+var rootFlags arrayFlags
+
+/* What to do with the registry file, instead of generating it. */
+type modeFlags struct {
+	reset    *bool
+	check    *bool
+	watch    *bool
+	interval *time.Duration
+	debounce *time.Duration
+}
+
+/* How the generated registry is declared, and where what was parsed is cached. */
+type registryFlags struct {
+	packageName  *string
+	functionName *string
+	buildTags    *string
+	cacheFile    *string
+}
+
+/* The options for generating constructors rather than the registry. */
+type constructorFlags struct {
+	file     *string
+	registry *string
+	config   *string
+	roots    arrayFlags
+}
+
 /* Only needed to fulfil flag interface. */
 func (i *arrayFlags) String() string {
 	return ""
@@ -27,13 +59,17 @@ func (i *arrayFlags) Set(value string) error {
 }
 
 func main() {
-	// @todo At least let the user know what's going on, no need for a progress bar
+	modes, dir, file, exclude, registry, constructors := declareCommandLineFlags()
 
-	reset, dir, file, exclude := declareCommandLineFlags()
+	if *constructors.file != "" {
+		generateConstructors(constructors)
+
+		return
+	}
 
 	gen := TypeRegistry.NewAutoRegistryGenerator(TypeRegistry.AutoGeneratedRegistryWriter{})
 
-	if *reset == true {
+	if *modes.reset == true {
 		gen.Reset(*file)
 
 		return
@@ -56,17 +92,182 @@ func main() {
 		panic(fmt.Sprintf("Could not retrieve absolute filepath for file: '%s', got error: '%s'", *file, err.Error()))
 	}
 
-	gen.Generate(absoluteFile, absoluteDir, exclude...)
+	/* Excluded directories are compared with the paths within the absolute directory, so they need to be absolute too. */
+	for i, excludeDir := range exclude {
+		if absoluteExcludeDir, err := filepath.Abs(excludeDir); err == nil {
+			exclude[i] = absoluteExcludeDir
+		}
+	}
+
+	options := TypeRegistry.Options{
+		Dir:          absoluteDir,
+		Exclude:      exclude,
+		PackageName:  *registry.packageName,
+		FunctionName: *registry.functionName,
+		BuildTags:    *registry.buildTags,
+		CacheFile:    *registry.cacheFile,
+		Warning:      printWarning,
+	}
+
+	if *modes.check {
+		checkRegistry(&gen, *file, absoluteFile, options)
+
+		return
+	}
+
+	if *modes.watch {
+		watchRegistry(&gen, absoluteFile, options, TypeRegistry.WatchOptions{
+			Interval: *modes.interval,
+			Debounce: *modes.debounce,
+		})
+
+		return
+	}
+
+	var progress TypeRegistry.Progress
+
+	options.Progress = reportProgress(&progress)
+
+	if err := gen.GenerateFile(absoluteFile, options); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Printf(
+		"[Info] - Generated the registry: %s from %d directories (%d parsed, %d cached) in %s\n",
+		*file, progress.Total, progress.Parsed, progress.Cached, progress.Elapsed.Round(time.Millisecond),
+	)
 }
 
-func declareCommandLineFlags() (reset *bool, dir *string, file *string, exclude arrayFlags) {
+/* Lets the user know what's going on every second or so, keeping the latest progress for the summary at the end. */
+func reportProgress(latest *TypeRegistry.Progress) func(TypeRegistry.Progress) {
+	var lastReport time.Time
+
+	return func(progress TypeRegistry.Progress) {
+		*latest = progress
+
+		if progress.Read == progress.Total || time.Since(lastReport) < time.Second {
+			return
+		}
+
+		lastReport = time.Now()
+
+		fmt.Printf("[Info] - Read %d of %d directories (%d parsed, %d cached)\n",
+			progress.Read, progress.Total, progress.Parsed, progress.Cached)
+	}
+}
+
+/* Exits with a non-zero status and lists what changed if the registry file is not the same as a new one would be. */
+func checkRegistry(
+	gen *TypeRegistry.AutoRegistryGenerator, file string, absoluteFile string, options TypeRegistry.Options,
+) {
+	difference, err := gen.Check(absoluteFile, options)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+
+	if difference == nil {
+		fmt.Printf("The registry: %s is up to date\n", file)
+
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "The registry: %s is out of date, run gen again to update it:\n%s", file, difference)
+	os.Exit(1)
+}
+
+/* Prints what generating the registry ignored, or might need fixing. */
+func printWarning(message string) {
+	fmt.Printf("[Warning] - %s\n", message)
+}
+
+/* Generates constructors for the roots using the registry that has already been generated. */
+func generateConstructors(constructors constructorFlags) {
+	if len(constructors.roots) == 0 {
+		panic("At least one -root is required to generate constructors")
+	}
+
+	absoluteFile, err := filepath.Abs(*constructors.file)
+
+	if err != nil {
+		panic(
+			fmt.Sprintf(
+				"Could not retrieve absolute filepath for file: '%s', got error: '%s'", *constructors.file, err.Error(),
+			),
+		)
+	}
+
+	absoluteRegistry, err := filepath.Abs(*constructors.registry)
+
+	if err != nil {
+		panic(
+			fmt.Sprintf(
+				"Could not retrieve absolute filepath for dir: '%s', got error: '%s'", *constructors.registry, err.Error(),
+			),
+		)
+	}
+
+	gen := TypeRegistry.NewConstructorGenerator()
+
+	if err := gen.Generate(absoluteFile, absoluteRegistry, *constructors.config, constructors.roots...); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
+
+/* Regenerates the registry whenever .go files change, until interrupted. */
+func watchRegistry(
+	gen *TypeRegistry.AutoRegistryGenerator, absoluteFile string, options TypeRegistry.Options,
+	watchOptions TypeRegistry.WatchOptions,
+) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+
+	defer stop()
+
+	fmt.Printf("[Watch] - Watching: %s for changes to the registry: %s\n", options.Dir, absoluteFile)
+
+	err := gen.Watch(ctx, absoluteFile, options, watchOptions, func(event TypeRegistry.WatchEvent) {
+		fmt.Printf("[Watch] %s - %s", time.Now().Format("15:04:05"), event)
+	})
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
+
+func declareCommandLineFlags() (
+	modes modeFlags,
+	dir *string,
+	file *string,
+	exclude arrayFlags,
+	registry registryFlags,
+	constructors constructorFlags,
+) {
 	exclude = excludeFlags
+	constructors.roots = rootFlags
 
-	reset = flag.Bool("reset", false, "Reset the registry file")
+	modes.reset = flag.Bool("reset", false, "Reset the registry file")
+	modes.check = flag.Bool("check", false, "Exit with a non-zero status if the registry file is not up to date")
+	modes.watch = flag.Bool("watch", false, "Keep running and regenerate the registry when .go files change")
+	modes.interval = flag.Duration("interval", 500*time.Millisecond, "How often to look for changes with -watch")
+	modes.debounce = flag.Duration("debounce", 300*time.Millisecond, "How long changes must stop for with -watch")
 	file = flag.String("o", "./Registry.go", "A relative or absolute filepath to write the registry to")
 	dir = flag.String("dir", ".", "A relative or absolute directory to recurse and generate the type registry from")
 	flag.Var(&exclude, "exclude", "Directories to exclude parsing for registry, such as vendor/")
 
+	registry.packageName = flag.String("package", "", "The package of the registry, guessed from -o if empty")
+	registry.functionName = flag.String("func", "GetRegistry", "The name of the function returning the registry")
+	registry.buildTags = flag.String("tags", "", "A build constraint for the registry file, like \"!production\"")
+	registry.cacheFile = flag.String("cache", "", "A file to cache what was parsed in, so only changes are parsed again")
+
+	constructors.file = flag.String("constructors", "", "A filepath to write constructors for each -root to, instead")
+	constructors.registry = flag.String("registry", ".", "The directory of the package with the generated registry")
+	constructors.config = flag.String("config", "", "A JSON configuration with the bindings and definitions to use")
+	flag.Var(&constructors.roots, "root", "A struct or interface name to generate a constructor for")
+
 	flag.Parse()
 
 	return
@@ -1,7 +1,17 @@
 module github.com/j7mbo/goij
 
+go 1.21
+
 require (
 	github.com/j7mbo/MethodCallRetrier v1.1.3
 	github.com/sirupsen/logrus v1.4.0
 	github.com/stretchr/testify v1.3.0
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/konsorten/go-windows-terminal-sequences v1.0.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/crypto v0.0.0-20180904163835-0709b304e793 // indirect
+	golang.org/x/sys v0.0.0-20180905080454-ebe1bf3edb33 // indirect
+)
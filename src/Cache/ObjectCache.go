@@ -14,19 +14,18 @@ type ObjectCache interface {
 
 type objectCache struct {
 	cachedObjs map[string]interface{}
+
+	/* Names of the types looked up, so they aren't formatted on every lookup. */
+	typeNames map[reflect.Type]string
 }
 
 func NewObjectCache() ObjectCache {
-	return &objectCache{cachedObjs: make(map[string]interface{})}
+	return &objectCache{cachedObjs: make(map[string]interface{}), typeNames: make(map[reflect.Type]string)}
 }
 
-/* If pointer passed in, it is dereferenced by getValue() so makes no difference in getting the type name. */
+/* If pointer passed in, it is dereferenced by getElem() so makes no difference in getting the type name. */
 func (r *objectCache) Store(obj interface{}) {
-	var typeName string
-
-	typeName = fmt.Sprintf("%s.%s", reflect.TypeOf(r.getValue(obj)).PkgPath(), reflect.TypeOf(r.getValue(obj)).Name())
-
-	r.cachedObjs[typeName] = obj
+	r.cachedObjs[r.typeName(r.getElem(obj).Type())] = obj
 }
 
 func (r *objectCache) FindByName(name string) interface{} {
@@ -39,10 +38,7 @@ func (r *objectCache) FindByName(name string) interface{} {
 
 /* Given a reflect value (when recursing around a struct's fields with reflect); find the object already stored. */
 func (r *objectCache) FindByValue(objType reflect.Value) interface{} {
-	typeName := reflect.TypeOf(objType.Interface()).Elem().Name()
-	typePkg := reflect.TypeOf(objType.Interface()).Elem().PkgPath()
-
-	return r.FindByName(fmt.Sprintf("%s.%s", typePkg, typeName))
+	return r.FindByName(r.typeName(objType.Type().Elem()))
 }
 
 /* Given a reflect value (when recursing around a struct's fields with reflect); find the object already stored. */
@@ -51,14 +47,23 @@ func (r *objectCache) FindByType(objType reflect.Type) interface{} {
 		objType = objType.Elem()
 	}
 
-	return r.FindByName(fmt.Sprintf("%s.%s", objType.PkgPath(), objType.Name()))
+	return r.FindByName(r.typeName(objType))
 }
 
-/* Given a value, loop through until we get a concrete element out of it. */
-func (r *objectCache) getValue(obj interface{}) interface{} {
-	return r.getElem(obj).Interface()
+/* The full name of a type, like "github.com/x/pkg.Server". */
+func (r *objectCache) typeName(objType reflect.Type) string {
+	if name, found := r.typeNames[objType]; found {
+		return name
+	}
+
+	name := fmt.Sprintf("%s.%s", objType.PkgPath(), objType.Name())
+
+	r.typeNames[objType] = name
+
+	return name
 }
 
+/* Given a value, loop through until we get a concrete element out of it. */
 func (r *objectCache) getElem(obj interface{}) reflect.Value {
 	val := reflect.ValueOf(obj)
 
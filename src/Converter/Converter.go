@@ -0,0 +1,321 @@
+package Converter
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* A user-provided conversion of any value to a single type. */
+type Conversion func(value interface{}) (interface{}, error)
+
+/*
+Converts definition values, such as those from environment variables and configuration files, to the type they are
+injected into.
+*/
+type Converter interface {
+	Register(target reflect.Type, conversion Conversion)
+	Convert(value interface{}, target reflect.Type) (reflect.Value, error)
+}
+
+type converter struct {
+	conversions map[reflect.Type]Conversion
+}
+
+/* The reflected types that get special treatment when converting from a string. */
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	urlType             = reflect.TypeOf(url.URL{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func NewConverter() Converter {
+	return &converter{conversions: make(map[reflect.Type]Conversion)}
+}
+
+/* Register a conversion for a type, which takes precedence over the built-in conversions. */
+func (c *converter) Register(target reflect.Type, conversion Conversion) {
+	c.conversions[target] = conversion
+}
+
+/*
+Convert a value to the target type. Values that are already assignable are returned as they are, otherwise these are
+tried in order:
+
+- A conversion registered for the target type
+- Referencing or dereferencing pointers
+- encoding.TextUnmarshaler for strings
+- Strings to bools, numbers, time.Duration, url.URL and comma separated slices
+- Numeric widening, and narrowing where the value doesn't overflow, but not to time.Duration as numbers have no unit
+- Named types with the same underlying kind, like "type Port int"
+- Slices, element by element
+*/
+func (c *converter) Convert(value interface{}, target reflect.Type) (reflect.Value, error) {
+	if value == nil {
+		switch target.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+			return reflect.Zero(target), nil
+		}
+
+		return reflect.Value{}, fmt.Errorf("nil cannot be converted to type: %s", target)
+	}
+
+	source := reflect.ValueOf(value)
+
+	if source.Type().AssignableTo(target) {
+		return source, nil
+	}
+
+	if conversion, found := c.conversions[target]; found {
+		return c.convertWithConversion(conversion, value, target)
+	}
+
+	/* A pointer was provided where a value is expected. */
+	if source.Kind() == reflect.Ptr && target.Kind() != reflect.Ptr {
+		if source.IsNil() {
+			return reflect.Value{}, fmt.Errorf("nil %s cannot be converted to type: %s", source.Type(), target)
+		}
+
+		return c.Convert(source.Elem().Interface(), target)
+	}
+
+	/* A value was provided where a pointer is expected. */
+	if target.Kind() == reflect.Ptr {
+		elem, err := c.Convert(value, target.Elem())
+
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		ptr := reflect.New(target.Elem())
+		ptr.Elem().Set(elem)
+
+		return ptr, nil
+	}
+
+	if source.Kind() == reflect.String {
+		return c.convertString(source.String(), target)
+	}
+
+	/* A number has no unit, so "timeout: 30" would silently be 30ns rather than the 30s that was probably meant. */
+	if isNumeric(source.Kind()) && target == durationType {
+		return reflect.Value{}, fmt.Errorf(
+			"value: %v of type: %s has no unit for type: %s, use a string like \"30s\"", value, source.Type(), target,
+		)
+	}
+
+	if isNumeric(source.Kind()) && isNumeric(target.Kind()) {
+		return convertNumber(source, target)
+	}
+
+	if source.Kind() == target.Kind() && source.Type().ConvertibleTo(target) {
+		return source.Convert(target), nil
+	}
+
+	if source.Kind() == reflect.Slice && target.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(target, 0, source.Len())
+
+		for i := 0; i < source.Len(); i++ {
+			elem, err := c.Convert(source.Index(i).Interface(), target.Elem())
+
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("element %d: %s", i, err.Error())
+			}
+
+			slice = reflect.Append(slice, elem)
+		}
+
+		return slice, nil
+	}
+
+	return reflect.Value{}, fmt.Errorf("value of type: %s cannot be converted to type: %s", source.Type(), target)
+}
+
+/* Call a user-provided conversion, checking that it really returned the type it was registered for. */
+func (c *converter) convertWithConversion(
+	conversion Conversion, value interface{}, target reflect.Type,
+) (reflect.Value, error) {
+	converted, err := conversion(value)
+
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("unable to convert: '%v' to type: %s, error: %s", value, target, err.Error())
+	}
+
+	if converted == nil || !reflect.TypeOf(converted).AssignableTo(target) {
+		return reflect.Value{}, fmt.Errorf("conversion for type: %s returned type: %T", target, converted)
+	}
+
+	return reflect.ValueOf(converted), nil
+}
+
+/* Parses a string into a value of the target type, splitting slices on commas. */
+func (c *converter) convertString(raw string, target reflect.Type) (reflect.Value, error) {
+	if reflect.PtrTo(target).Implements(textUnmarshalerType) {
+		ptr := reflect.New(target)
+
+		if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw)); err != nil {
+			return reflect.Value{}, fmt.Errorf("unable to convert: '%s' to type: %s, error: %s", raw, target, err.Error())
+		}
+
+		return ptr.Elem(), nil
+	}
+
+	var value interface{}
+	var err error
+
+	switch {
+	case target == durationType:
+		value, err = time.ParseDuration(raw)
+	case target == urlType:
+		var parsed *url.URL
+
+		if parsed, err = url.Parse(raw); err == nil {
+			value = *parsed
+		}
+	case target.Kind() == reflect.String:
+		value = raw
+	case target.Kind() == reflect.Bool:
+		value, err = strconv.ParseBool(raw)
+	case isInt(target.Kind()):
+		value, err = strconv.ParseInt(raw, 10, target.Bits())
+	case isUint(target.Kind()):
+		value, err = strconv.ParseUint(raw, 10, target.Bits())
+	case isFloat(target.Kind()):
+		value, err = strconv.ParseFloat(raw, target.Bits())
+	case target.Kind() == reflect.Slice:
+		slice := reflect.MakeSlice(target, 0, 0)
+
+		if strings.TrimSpace(raw) == "" {
+			return slice, nil
+		}
+
+		for _, part := range strings.Split(raw, ",") {
+			elem, err := c.Convert(strings.TrimSpace(part), target.Elem())
+
+			if err != nil {
+				return reflect.Value{}, err
+			}
+
+			slice = reflect.Append(slice, elem)
+		}
+
+		return slice, nil
+	default:
+		return reflect.Value{}, fmt.Errorf("a string cannot be converted to type: %s", target)
+	}
+
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("unable to convert: '%s' to type: %s", raw, target)
+	}
+
+	/* Handles named types, like "type Port int", as well as narrowing int64 to int etc. */
+	return reflect.ValueOf(value).Convert(target), nil
+}
+
+/* Converts between numeric types, refusing to lose precision or overflow. */
+func convertNumber(source reflect.Value, target reflect.Type) (reflect.Value, error) {
+	result := reflect.New(target).Elem()
+
+	overflowError := fmt.Errorf("value: %v of type: %s overflows type: %s", source.Interface(), source.Type(), target)
+
+	switch {
+	case isInt(target.Kind()):
+		var i int64
+
+		switch {
+		case isInt(source.Kind()):
+			i = source.Int()
+		case isUint(source.Kind()):
+			if source.Uint() > math.MaxInt64 {
+				return reflect.Value{}, overflowError
+			}
+
+			i = int64(source.Uint())
+		default:
+			if source.Float() != math.Trunc(source.Float()) {
+				return reflect.Value{}, fmt.Errorf("value: %v is not a whole number for type: %s", source.Float(), target)
+			}
+
+			if source.Float() < math.MinInt64 || source.Float() >= math.MaxInt64 {
+				return reflect.Value{}, overflowError
+			}
+
+			i = int64(source.Float())
+		}
+
+		if result.OverflowInt(i) {
+			return reflect.Value{}, overflowError
+		}
+
+		result.SetInt(i)
+	case isUint(target.Kind()):
+		var u uint64
+
+		switch {
+		case isInt(source.Kind()):
+			if source.Int() < 0 {
+				return reflect.Value{}, overflowError
+			}
+
+			u = uint64(source.Int())
+		case isUint(source.Kind()):
+			u = source.Uint()
+		default:
+			if source.Float() != math.Trunc(source.Float()) {
+				return reflect.Value{}, fmt.Errorf("value: %v is not a whole number for type: %s", source.Float(), target)
+			}
+
+			if source.Float() < 0 || source.Float() >= math.MaxUint64 {
+				return reflect.Value{}, overflowError
+			}
+
+			u = uint64(source.Float())
+		}
+
+		if result.OverflowUint(u) {
+			return reflect.Value{}, overflowError
+		}
+
+		result.SetUint(u)
+	default:
+		var f float64
+
+		switch {
+		case isInt(source.Kind()):
+			f = float64(source.Int())
+		case isUint(source.Kind()):
+			f = float64(source.Uint())
+		default:
+			f = source.Float()
+		}
+
+		if result.OverflowFloat(f) {
+			return reflect.Value{}, overflowError
+		}
+
+		result.SetFloat(f)
+	}
+
+	return result, nil
+}
+
+func isInt(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Int64
+}
+
+func isUint(kind reflect.Kind) bool {
+	return kind >= reflect.Uint && kind <= reflect.Uintptr
+}
+
+func isFloat(kind reflect.Kind) bool {
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
+
+func isNumeric(kind reflect.Kind) bool {
+	return isInt(kind) || isUint(kind) || isFloat(kind)
+}
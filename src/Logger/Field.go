@@ -0,0 +1,113 @@
+package Logger
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+/* Level is the severity of a log message. */
+type Level int
+
+const (
+	/* Every step of the resolution, like each field found on a struct. */
+	LevelTrace Level = iota
+
+	/* Decisions taken during resolution, like the definition, delegate or binding used. */
+	LevelDebug
+
+	/* What the end user asked the injector to do, like Make() a type. */
+	LevelInfo
+
+	/* Something that was ignored and may not be what the end user intended. */
+	LevelWarn
+
+	/* A failure to resolve, just before the injector panics. */
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelTrace:
+		return "TRACE"
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	}
+
+	return fmt.Sprintf("LEVEL(%d)", int(l))
+}
+
+/* Keys of the fields the injector logs with. */
+const (
+	KeyType     = "type"
+	KeyField    = "field"
+	KeySource   = "source"
+	KeyDuration = "duration"
+)
+
+/* Field is a structured key-value pair logged with a message. The value is only formatted when it is written. */
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+/* Any creates a field with any key and value. */
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+/* Type creates a "type" field with the type of a value, or the value itself if it is a reflect.Type. */
+func Type(value interface{}) Field {
+	return Field{Key: KeyType, Value: TypeOf(value)}
+}
+
+/* TypeOf returns the type of a value, or the value itself if it is a reflect.Type, for a field with another key. */
+func TypeOf(value interface{}) reflect.Type {
+	if t, isType := value.(reflect.Type); isType {
+		return t
+	}
+
+	return reflect.TypeOf(value)
+}
+
+/* FieldName creates a "field" field with the name of a struct field or function argument. */
+func FieldName(name string) Field {
+	return Field{Key: KeyField, Value: name}
+}
+
+/* Source creates a "source" field with where a value came from, like a definition, delegate or the registry. */
+func Source(source interface{}) Field {
+	return Field{Key: KeySource, Value: source}
+}
+
+/* Duration creates a "duration" field with how long something took. */
+func Duration(duration time.Duration) Field {
+	return Field{Key: KeyDuration, Value: duration}
+}
+
+/* Resolved returns the value in a form any logging library can encode, with types as their name. */
+func (f Field) Resolved() interface{} {
+	if t, isType := f.Value.(reflect.Type); isType {
+		return fmt.Sprint(t)
+	}
+
+	return f.Value
+}
+
+/* String formats the value, quoting it if it contains spaces so it can be told apart from the next field. */
+func (f Field) String() string {
+	value := fmt.Sprint(f.Resolved())
+
+	if strings.ContainsAny(value, " \t\n\"") {
+		return fmt.Sprintf("%q", value)
+	}
+
+	return value
+}
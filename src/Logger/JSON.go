@@ -0,0 +1,75 @@
+package Logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+/*
+A Logger that writes each message as a line of JSON, for environments without a structured logging library.
+
+Example line: {"time":"2006-01-02T15:04:05Z","level":"DEBUG","msg":"Injecting definition","field":"Port","type":"int"}
+*/
+type jsonLogger struct {
+	/* Lines are written whole, so that messages logged at the same time are not interleaved. */
+	mutex sync.Mutex
+
+	writer io.Writer
+
+	/* Messages below this level are not logged. */
+	level Level
+}
+
+/* Create a new Logger that writes JSON lines to a writer, like os.Stderr, for messages from the given level. */
+func NewJSONLogger(writer io.Writer, level Level) Logger {
+	return &jsonLogger{writer: writer, level: level}
+}
+
+func (l *jsonLogger) Enabled(level Level) bool {
+	return level >= l.level
+}
+
+func (l *jsonLogger) Log(level Level, msg string, fields ...Field) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	var line bytes.Buffer
+
+	line.WriteString("{")
+	writeJSONField(&line, "time", time.Now().UTC().Format(time.RFC3339Nano))
+	line.WriteString(",")
+	writeJSONField(&line, "level", level.String())
+	line.WriteString(",")
+	writeJSONField(&line, "msg", msg)
+
+	for _, field := range fields {
+		line.WriteString(",")
+		writeJSONField(&line, field.Key, field.Resolved())
+	}
+
+	line.WriteString("}\n")
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	_, _ = l.writer.Write(line.Bytes())
+}
+
+/* Writes a key and it's value, falling back to the value as text if it can't be encoded. */
+func writeJSONField(line *bytes.Buffer, key string, value interface{}) {
+	encodedKey, _ := json.Marshal(key)
+	encodedValue, err := json.Marshal(value)
+
+	if err != nil {
+		encodedValue, _ = json.Marshal(fmt.Sprint(value))
+	}
+
+	line.Write(encodedKey)
+	line.WriteString(":")
+	line.Write(encodedValue)
+}
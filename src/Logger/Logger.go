@@ -1,52 +1,104 @@
 package Logger
 
 import (
-	"fmt"
 	"log"
 	"os"
+	"strings"
 )
 
 /* Prefix for all default log messages. */
-const defaultLoggerPrefix = "[Injector Log] [%s] - "
+const defaultLoggerPrefix = "[Injector Log] "
 
 /*
-A generic Logger that can be hooked into by the end user if required.
+Logger is what the injector logs to, and can be implemented by the end user to hook into any logging library.
 
-Example usage: Logger.SetDebugLogger(log.Println)
+Messages are constant, with the details in structured fields. The injector checks Enabled() before building a message
+and it's fields, so nothing is formatted for a level that is disabled.
 */
-type Logger struct {
-	/* Logging debug messages with this - can be user provided. */
+type Logger interface {
+	/* Whether messages at the given level are logged. */
+	Enabled(level Level) bool
+
+	/* Log a message at the given level with structured key-value fields. */
+	Log(level Level, msg string, fields ...Field)
+}
+
+/*
+A Logger that writes messages and their fields as text to logging functions provided by the end user.
+
+Example usage: Logger.New(log.Println, log.Println)
+*/
+type FuncLogger struct {
+	/* Logging trace, debug and info messages with this - can be user provided. */
 	debugLog func(...interface{})
-	/* Logging error messages with this - can be user provided. */
+	/* Logging warn and error messages with this - can be user provided. */
 	errorLog func(...interface{})
+	/* Messages below this level are not logged. */
+	level Level
 }
 
-/* Create a new Logger with user-provided logging functions. */
-func New(debugLog func(...interface{}), errorLog func(...interface{})) Logger {
-	return Logger{debugLog: debugLog, errorLog: errorLog}
+/*
+Create a new Logger with user-provided logging functions, logging every level.
+
+A nil function disables the levels it would log.
+*/
+func New(debugLog func(...interface{}), errorLog func(...interface{})) FuncLogger {
+	return FuncLogger{debugLog: debugLog, errorLog: errorLog, level: LevelTrace}
 }
 
 /* Create a new Logger which defaults logging to stdout / stderr. */
-func NewStdLogger() *Logger {
-	return &Logger{debugLog: getDefaultDebugLogger(), errorLog: getDefaultErrorLogger()}
+func NewStdLogger() *FuncLogger {
+	return &FuncLogger{debugLog: getDefaultDebugLogger(), errorLog: getDefaultErrorLogger(), level: LevelTrace}
+}
+
+/* SetLevel stops messages below the given level from being logged. */
+func (l *FuncLogger) SetLevel(level Level) {
+	l.level = level
+}
+
+func (l *FuncLogger) Enabled(level Level) bool {
+	return level >= l.level && l.logFunc(level) != nil
+}
+
+func (l *FuncLogger) Log(level Level, msg string, fields ...Field) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	l.logFunc(level)(Format(level, msg, fields...))
 }
 
-/* Write a debug log. */
-func (l *Logger) Debug(msg string) {
-	l.debugLog(msg)
+func (l *FuncLogger) logFunc(level Level) func(...interface{}) {
+	if level >= LevelWarn {
+		return l.errorLog
+	}
+
+	return l.debugLog
 }
 
-/* Write an error log. */
-func (l *Logger) Error(msg string) {
-	l.errorLog(msg)
+/* Format renders a message and it's fields as text, like: "[DEBUG] - Calling delegate type=func() *app.Server". */
+func Format(level Level, msg string, fields ...Field) string {
+	var builder strings.Builder
+
+	builder.WriteString("[" + level.String() + "] - ")
+	builder.WriteString(msg)
+
+	for _, field := range fields {
+		builder.WriteString(" ")
+		builder.WriteString(field.Key)
+		builder.WriteString("=")
+		builder.WriteString(field.String())
+	}
+
+	return builder.String()
 }
 
 /* Default to stdout. */
 func getDefaultDebugLogger() func(...interface{}) {
-	return log.New(os.Stdout, fmt.Sprintf(defaultLoggerPrefix, "DEBUG"), 0).Println
+	return log.New(os.Stdout, defaultLoggerPrefix, 0).Println
 }
 
 /* Default to stderr. */
 func getDefaultErrorLogger() func(...interface{}) {
-	return log.New(os.Stdout, fmt.Sprintf(defaultLoggerPrefix, "ERROR"), 0).Println
+	return log.New(os.Stderr, defaultLoggerPrefix, 0).Println
 }
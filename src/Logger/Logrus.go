@@ -0,0 +1,44 @@
+package Logger
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+/* A Logger that logs to a *logrus.Logger, with the fields as logrus fields. */
+type logrusLogger struct {
+	logger *logrus.Logger
+}
+
+/* Create a new Logger that logs to a *logrus.Logger. */
+func NewLogrusLogger(logger *logrus.Logger) Logger {
+	return &logrusLogger{logger: logger}
+}
+
+func (l *logrusLogger) Enabled(level Level) bool {
+	return l.logger.IsLevelEnabled(toLogrusLevel(level))
+}
+
+func (l *logrusLogger) Log(level Level, msg string, fields ...Field) {
+	logrusFields := make(logrus.Fields, len(fields))
+
+	for _, field := range fields {
+		logrusFields[field.Key] = field.Resolved()
+	}
+
+	l.logger.WithFields(logrusFields).Log(toLogrusLevel(level), msg)
+}
+
+func toLogrusLevel(level Level) logrus.Level {
+	switch level {
+	case LevelTrace:
+		return logrus.TraceLevel
+	case LevelDebug:
+		return logrus.DebugLevel
+	case LevelInfo:
+		return logrus.InfoLevel
+	case LevelWarn:
+		return logrus.WarnLevel
+	}
+
+	return logrus.ErrorLevel
+}
@@ -0,0 +1,48 @@
+package Logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+/* The slog level for trace messages, as slog has no trace level of it's own. */
+const SlogLevelTrace = slog.LevelDebug - 4
+
+/* A Logger that logs to a *slog.Logger, with the fields as attributes. */
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+/* Create a new Logger that logs to a *slog.Logger. Trace messages are logged at SlogLevelTrace. */
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return &slogLogger{logger: logger}
+}
+
+func (l *slogLogger) Enabled(level Level) bool {
+	return l.logger.Enabled(context.Background(), toSlogLevel(level))
+}
+
+func (l *slogLogger) Log(level Level, msg string, fields ...Field) {
+	attributes := make([]slog.Attr, len(fields))
+
+	for i, field := range fields {
+		attributes[i] = slog.Any(field.Key, field.Resolved())
+	}
+
+	l.logger.LogAttrs(context.Background(), toSlogLevel(level), msg, attributes...)
+}
+
+func toSlogLevel(level Level) slog.Level {
+	switch level {
+	case LevelTrace:
+		return SlogLevelTrace
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelInfo:
+		return slog.LevelInfo
+	case LevelWarn:
+		return slog.LevelWarn
+	}
+
+	return slog.LevelError
+}
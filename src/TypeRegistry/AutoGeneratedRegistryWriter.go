@@ -1,28 +1,21 @@
 package TypeRegistry
 
 import (
+	"bytes"
 	"fmt"
-	"math/rand"
+	"go/format"
+	"go/token"
+	"go/types"
+	"io"
 	"os"
-	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
-	"text/template"
-	"time"
-)
-
-/* Basically writing my own template because I haven't been bothered to learn the template package yet. */
-var (
-	fileTop = "package main\n"
-
-	fileImports = "import \"github.com/j7mbo/goij/src/TypeRegistry\"\n"
-	funcDef     = "\nfunc GetRegistry() (registry TypeRegistry.Registry) {\n"
-	funcBottom  = "\n    return\n" +
-		"}\n"
+	"unicode"
 )
 
 /* Default file contents for resetting. */
-var (
-	defaultFile = `package main
+const defaultFile = `package main
 
 import "github.com/j7mbo/goij/src/TypeRegistry"
 
@@ -31,7 +24,6 @@ func GetRegistry() (registry TypeRegistry.Registry) {
 }
 
 `
-)
 
 /* An object responsible for writing packageData structs to a file given the template above. */
 type AutoGeneratedRegistryWriter struct{}
@@ -53,108 +45,186 @@ func (*AutoGeneratedRegistryWriter) WriteDefaultFile(filePath string) {
 	}
 }
 
-/* Write the results of []packageData to a readable to file. Could've used the AST for this, but I'm lazy... */
-func (*AutoGeneratedRegistryWriter) WriteAutoGeneratedDataToFile(packageDataList []packageData, filePath string) {
-	dir, _ := filepath.Split(filePath)
-
-	importPath, err := retrieveImportPath(dir)
+/* Write the registry for the packages to the writer, declared with the package, function and build tags of options. */
+func (*AutoGeneratedRegistryWriter) WriteRegistry(
+	writer io.Writer, packageDataList []packageData, options Options,
+) error {
+	source, err := registrySource(options, packageDataList)
 
 	if err != nil {
-		/* We can't update the package name, let the user know they need to do this themselves... */
-		fmt.Println(fmt.Sprintf("[Info] - Don't forget to set the package name in the generated structRegistry file."))
-	} else {
-		/*
-			Guess the import path: This could be wrong if the structRegistry was generated into the project directory (main).
-			There's no way I can see to reliably figure out if the root of the project was the one chosen.
-		*/
-		importName := strings.Split(importPath, "/")[len(strings.Split(importPath, "/"))-1]
-
-		fileTop = fmt.Sprintf("package %s\n", importName)
+		return fmt.Errorf("Unable to format auto generated type structRegistry, error: %s", err.Error())
 	}
 
-	file, err := os.Create(filePath)
+	if _, err := writer.Write(source); err != nil {
+		return fmt.Errorf("Unable to write auto generated type structRegistry, error: %s", err.Error())
+	}
 
-	defer func() { _ = file.Close() }()
+	return nil
+}
 
-	if err != nil {
-		panic(fmt.Sprintf("Unable to write auto generated type structRegistry to file: %s, error: %s", filePath, err.Error()))
-	}
+/*
+Writes the registry for the packages, which is the same for the same packages however they were found: packages are
+sorted by import path, and types and factories by name, each package is imported with an alias derived from it's path,
+and the file is formatted like gofmt would.
+*/
+func registrySource(options Options, packageDataList []packageData) ([]byte, error) {
+	packageDataList = append([]packageData(nil), packageDataList...)
+
+	sort.SliceStable(packageDataList, func(i, j int) bool {
+		return packageDataList[i].ImportPath < packageDataList[j].ImportPath
+	})
+
+	var imports, structs, interfaces, factories, arguments bytes.Buffer
+
+	/*
+		The TypeRegistry package is always imported, and the function and it's result are declared in the file, so no
+		package can have their names as an alias.
+	*/
+	aliases := map[string]bool{"TypeRegistry": true, "registry": true, options.FunctionName: true}
 
 	for _, packageData := range packageDataList {
-		/* May have 0 structs, but factories can return interfaces... */
-		if len(packageData.Structs) == 0 && len(packageData.Interfaces) == 0 {
+		/* May have 0 structs, but factories can return interfaces, or types from other packages... */
+		if len(packageData.Structs) == 0 && len(packageData.Interfaces) == 0 && len(packageData.Factories) == 0 {
 			continue
 		}
 
-		/* Alias for the import so multiple of the same package name will not be an issue. */
-		alias := generateRandomString(8)
+		alias := ImportAlias(packageData.ImportPath, func(alias string) bool { return aliases[alias] })
+		aliases[alias] = true
 
-		fileImports += fmt.Sprintf("import %s \"%s\"\n", alias, packageData.ImportPath)
+		fmt.Fprintf(&imports, "\t%s %q\n", alias, packageData.ImportPath)
 
-		for _, structName := range packageData.Structs {
-			mapKey := fmt.Sprintf("%s.%s", packageData.ImportPath, structName)
-			mapType := fmt.Sprintf("%s.%s", alias, structName)
+		for _, structName := range sortedStrings(packageData.Structs) {
+			fmt.Fprintf(
+				&structs,
+				"\tregistry.RegistryStructs = append(registry.RegistryStructs, TypeRegistry.RegistryStruct{Name: %q, Implementation: %s.%s{}})\n",
+				packageData.ImportPath+"."+structName, alias, structName,
+			)
+		}
 
-			funcDef += fmt.Sprintf(
-				"    registry.RegistryStructs = append(registry.RegistryStructs, TypeRegistry.RegistryStruct{ Name: \"%s\", Implementation: %s{}})\n",
-				mapKey, mapType,
+		for _, interfaceName := range sortedStrings(packageData.Interfaces) {
+			fmt.Fprintf(
+				&interfaces,
+				"\tregistry.RegistryInterfaces = append(registry.RegistryInterfaces, TypeRegistry.RegistryInterface{Name: %q, Implementation: (*%s.%s)(nil)})\n",
+				packageData.ImportPath+"."+interfaceName, alias, interfaceName,
 			)
 		}
 
-		for _, interfaceName := range packageData.Interfaces {
-			mapKey := fmt.Sprintf("%s.%s", packageData.ImportPath, interfaceName)
-			mapType := fmt.Sprintf("%s.%s", alias, interfaceName)
+		/* The factories of the package, by the type they return, so that there is one registry entry for each type. */
+		implementations := make(map[string][]string)
 
-			funcDef += fmt.Sprintf(
-				"    registry.RegistryInterfaces = append(registry.RegistryInterfaces, TypeRegistry.RegistryInterface{ Name: \"%s\", Implementation: (*%s)(nil)})\n",
-				mapKey, mapType,
+		factoryList := append([]factory(nil), packageData.Factories...)
+
+		sort.SliceStable(factoryList, func(i, j int) bool { return factoryList[i].MethodName < factoryList[j].MethodName })
+
+		for _, factory := range factoryList {
+			mapKey := fmt.Sprintf("%s.%s", factory.ReturnImportPath, factory.ReturnType)
+
+			implementations[mapKey] = append(implementations[mapKey], alias+"."+factory.MethodName)
+
+			if len(factory.Arguments) == 0 {
+				continue
+			}
+
+			fmt.Fprintf(
+				&arguments,
+				"\tregistry.RegistryFactoryArguments = append(registry.RegistryFactoryArguments, TypeRegistry.RegistryFactoryArguments{Name: %q, Arguments: []string{%s}})\n",
+				packageData.ImportPath+"."+factory.MethodName, quotedList(factory.Arguments),
 			)
 		}
 
-		if len(packageData.Factories) == 0 {
-			continue
+		for _, mapKey := range sortedKeys(implementations) {
+			fmt.Fprintf(
+				&factories,
+				"\tregistry.RegistryFactories = append(registry.RegistryFactories, TypeRegistry.RegistryFactory{Name: %q, Implementations: []interface{}{%s}})\n",
+				mapKey, strings.Join(sortedStrings(implementations[mapKey]), ", "),
+			)
 		}
+	}
+
+	var file bytes.Buffer
 
-		existingFactories := make(map[string]string)
+	if options.BuildTags != "" {
+		fmt.Fprintf(&file, "//go:build %s\n\n", options.BuildTags)
+	}
 
-		for _, factory := range packageData.Factories {
-			mapKey := fmt.Sprintf("%s.%s", packageData.ImportPath, factory.ReturnType)
-			mapType := fmt.Sprintf("%s.%s", alias, factory.MethodName)
+	fmt.Fprintf(&file, "package %s\n", options.PackageName)
+	file.WriteString("\nimport (\n\t\"github.com/j7mbo/goij/src/TypeRegistry\"\n")
+	file.Write(imports.Bytes())
+	fmt.Fprintf(&file, ")\n\nfunc %s() (registry TypeRegistry.Registry) {\n", options.FunctionName)
+	file.Write(structs.Bytes())
+	file.Write(interfaces.Bytes())
+	file.Write(arguments.Bytes())
+	file.Write(factories.Bytes())
+	file.WriteString("\n\treturn\n}\n")
+
+	return format.Source(file.Bytes())
+}
 
-			if _, exists := existingFactories[mapKey]; !exists {
-				/* Adds this only once, then later we replace the escaped %s with the individual factories. */
-				existingFactories[mapKey] = fmt.Sprintf(
-					"    registry.RegistryFactories = append(registry.RegistryFactories, TypeRegistry.RegistryFactory{ Name: \"%s\", Implementations: []interface{}{ <TYPE> }})\n",
-					mapKey,
-				)
-			}
+/*
+ImportAlias is the alias to import a package with in generated code, which is the last element of it's path without any
+major version, like "redis" for "github.com/go-redis/redis/v8", followed by a number if it is taken or is a predeclared
+identifier like "error". It is used for both the registry and the constructors, so that they alias packages the same.
+*/
+func ImportAlias(importPath string, isTaken func(alias string) bool) string {
+	elements := strings.Split(importPath, "/")
+	base := elements[len(elements)-1]
+
+	if isMajorVersion(base) && len(elements) > 1 {
+		base = elements[len(elements)-2]
+	}
 
-			existingFactories[mapKey] = strings.Replace(existingFactories[mapKey], "<TYPE>", mapType+", <TYPE>", 1)
+	base = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
 		}
 
-		for _, factoryString := range existingFactories {
-			funcDef += factoryString
-		}
+		return '_'
+	}, base)
 
-		funcDef = strings.Replace(funcDef, ", <TYPE>", "", -1)
+	switch {
+	/* A lone "_" would be a blank import, which can't be referred to. */
+	case base == "" || base == "_":
+		base = "pkg"
+	case unicode.IsDigit(rune(base[0])) || token.IsKeyword(base):
+		base = "_" + base
 	}
 
-	fileContents := fileTop + fileImports + funcDef + funcBottom
+	alias := base
 
-	_ = template.Must(template.New("").Parse(fileContents)).Execute(file, struct{}{})
+	for i := 2; isTaken(alias) || types.Universe.Lookup(alias) != nil; i++ {
+		alias = fmt.Sprintf("%s%d", base, i)
+	}
+
+	return alias
+}
+
+func sortedStrings(values []string) []string {
+	sorted := append([]string(nil), values...)
+
+	sort.Strings(sorted)
+
+	return sorted
 }
 
-/* Generate a pseudorandom alphanumeric string of a fixed length. */
-func generateRandomString(length int) string {
-	rand.Seed(time.Now().UnixNano())
+func sortedKeys(values map[string][]string) []string {
+	keys := make([]string, 0, len(values))
+
+	for key := range values {
+		keys = append(keys, key)
+	}
 
-	var letters = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
+	sort.Strings(keys)
+
+	return keys
+}
 
-	b := make([]rune, length)
+/* A list of Go string literals, like: "a", "b". */
+func quotedList(values []string) string {
+	quoted := make([]string, len(values))
 
-	for i := range b {
-		b[i] = letters[rand.Intn(len(letters))]
+	for i, value := range values {
+		quoted[i] = strconv.Quote(value)
 	}
 
-	return string(b)
+	return strings.Join(quoted, ", ")
 }
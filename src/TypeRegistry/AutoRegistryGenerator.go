@@ -1,15 +1,19 @@
 package TypeRegistry
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"go/ast"
+	"go/build/constraint"
 	"go/parser"
 	"go/token"
+	"go/types"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -23,25 +27,139 @@ func NewAutoRegistryGenerator(registryWriter AutoGeneratedRegistryWriter) AutoRe
 	return AutoRegistryGenerator{registryWriter: &registryWriter}
 }
 
+/* Options are the directories GenerateTo() scans, and how the generated registry is declared. */
+type Options struct {
+	/* The root directory to start recursing through. */
+	Dir string
+
+	/* Directories to ignore, such as vendor/. */
+	Exclude []string
+
+	/* The package of the generated file, "main" if empty. */
+	PackageName string
+
+	/* The function returning the registry, "GetRegistry" if empty. */
+	FunctionName string
+
+	/* A build constraint for the generated file, like "!production", written as a //go:build line if not empty. */
+	BuildTags string
+
+	/* A file to keep what was parsed from each directory in, so that only those that changed are parsed next time. */
+	CacheFile string
+
+	/* Called after each directory is read, with how far generating has got. */
+	Progress func(Progress)
+
+	/*
+		Called with what was ignored, like packages outside of any module, and anything else that might need fixing.
+		It is called in the order of the directories, after they have all been read, rather than while they are read.
+	*/
+	Warning func(message string)
+
+	/* What was parsed from each directory, kept in memory by Watch() between regenerations rather than in CacheFile. */
+	parseCache *parseCache
+}
+
+/* Passes a warning to options.Warning, if there is one. */
+func (o Options) warn(format string, args ...interface{}) {
+	if o.Warning != nil {
+		o.Warning(fmt.Sprintf(format, args...))
+	}
+}
+
 /*
 Generate a registry of all types within the application, given a root directory to recurse.
 
-The file argument is the file to write the registry to.
+The file argument is the file to write the registry to, and it's package name is guessed from it's directory.
 The dirPath option is the root directory to start recursing through.
 The ignoreDirs option is to pass in a list of directories to ignore such as vendor/.
 */
 func (g *AutoRegistryGenerator) Generate(file string, dirPath string, ignoreDirs ...string) {
-	dirPaths := findDirPathsRecursively(dirPath, ignoreDirs)
+	if err := g.GenerateFile(file, Options{Dir: dirPath, Exclude: ignoreDirs}); err != nil {
+		panic(err.Error())
+	}
+}
+
+/* GenerateFile writes the registry to a file, guessing the package name from it's directory if it isn't an option. */
+func (g *AutoRegistryGenerator) GenerateFile(file string, options Options) error {
+	if options.PackageName == "" {
+		options.PackageName = guessPackageName(file, options)
+	}
+
+	var registry bytes.Buffer
+
+	if err := g.GenerateTo(&registry, options); err != nil {
+		return err
+	}
 
-	var packageDataList []packageData
+	if err := os.WriteFile(file, registry.Bytes(), 0644); err != nil {
+		return fmt.Errorf("Unable to write auto generated type structRegistry to file: %s, error: %s", file, err.Error())
+	}
+
+	return nil
+}
 
-	for _, dirPath := range dirPaths {
-		for _, pckData := range g.retrievePackageInformation(dirPath) {
-			packageDataList = append(packageDataList, pckData)
+/*
+GenerateTo writes a registry of all types within options.Dir to the writer. Nothing is kept between calls, apart from in
+options.CacheFile if there is one, so it can be called as many times as needed, like when embedding it in another tool.
+*/
+func (g *AutoRegistryGenerator) GenerateTo(writer io.Writer, options Options) error {
+	if options.PackageName == "" {
+		options.PackageName = "main"
+	}
+
+	if options.FunctionName == "" {
+		options.FunctionName = "GetRegistry"
+	}
+
+	if !token.IsIdentifier(options.PackageName) || !token.IsIdentifier(options.FunctionName) {
+		return fmt.Errorf(
+			"Unable to generate a registry in package: '%s' with function: '%s', they must be identifiers",
+			options.PackageName, options.FunctionName,
+		)
+	}
+
+	if options.BuildTags != "" {
+		if _, err := constraint.Parse("//go:build " + options.BuildTags); err != nil {
+			return fmt.Errorf("Unable to use build tags: '%s', error: %s", options.BuildTags, err.Error())
 		}
 	}
 
-	g.registryWriter.WriteAutoGeneratedDataToFile(packageDataList, file)
+	dirPaths, err := findDirPathsRecursively(options.Dir, options.Exclude)
+
+	if err != nil {
+		return err
+	}
+
+	/* The go.mod of each module is only read once for all of it's directories. */
+	importPaths := newImportPathResolver()
+
+	packageDataList, err := g.readPackages(dirPaths, importPaths, options)
+
+	if err != nil {
+		return err
+	}
+
+	return g.registryWriter.WriteRegistry(writer, packageDataList, options)
+}
+
+/*
+Guess the package of a registry file from the import path of it's directory. This could be wrong if the registry was
+generated into the project directory (main), and there's no way to reliably figure out if that was the one chosen.
+*/
+func guessPackageName(file string, options Options) string {
+	dir, _ := filepath.Split(file)
+
+	importPath, err := retrieveImportPath(dir)
+
+	if err != nil {
+		/* We can't update the package name, let the user know they need to do this themselves... */
+		options.warn("Don't forget to set the package name in the generated registry file: %s", file)
+
+		return "main"
+	}
+
+	return importPath[strings.LastIndex(importPath, "/")+1:]
 }
 
 /* Reset the generated file to defaults. */
@@ -56,12 +174,18 @@ func (g *AutoRegistryGenerator) Reset(filePath string) {
 }
 
 /* Fine all directory paths recursively given a top-level directory. */
-func findDirPathsRecursively(dirPath string, ignoreDirs []string) (dirs []string) {
-	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
-		for _, ignoreDir := range ignoreDirs {
-			if strings.HasPrefix(path, ignoreDir) {
-				return nil
-			}
+func findDirPathsRecursively(dirPath string, ignoreDirs []string) (dirs []string, err error) {
+	err = filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if isExcluded(path, ignoreDirs) {
+			return nil
+		}
+
+		if info.IsDir() && path != dirPath && ignoredByGo(info.Name()) {
+			return filepath.SkipDir
 		}
 
 		if info.IsDir() {
@@ -72,10 +196,29 @@ func findDirPathsRecursively(dirPath string, ignoreDirs []string) (dirs []string
 	})
 
 	if err != nil {
-		panic("Unable to automatically register types due to a directory reading error: " + err.Error())
+		return nil, errors.New("Unable to automatically register types due to a directory reading error: " + err.Error())
 	}
 
-	return
+	return dirs, nil
+}
+
+/*
+Whether the go command ignores a directory, like the "_goij_constructors" directory of the constructor program, as
+packages in them can't be imported.
+*/
+func ignoredByGo(dirName string) bool {
+	return strings.HasPrefix(dirName, ".") || strings.HasPrefix(dirName, "_") || dirName == "testdata"
+}
+
+/* Whether a path is within one of the directories to ignore. */
+func isExcluded(path string, ignoreDirs []string) bool {
+	for _, ignoreDir := range ignoreDirs {
+		if strings.HasPrefix(path, ignoreDir) {
+			return true
+		}
+	}
+
+	return false
 }
 
 /* Simple struct to group a package's name with it's import path. */
@@ -100,17 +243,26 @@ type factory struct {
 	/* Factory method name. */
 	MethodName string
 
-	/* Return object within this package. */
+	/* The import path of the package of the returned type, which can be another package. */
+	ReturnImportPath string
+
+	/* The name of the returned type, like "Server" or "Box[int]" for an instantiated generic type. */
 	ReturnType string
+
+	/* The names of the factory's arguments, as these cannot be retrieved with reflection. */
+	Arguments []string
 }
 
-func (g *AutoRegistryGenerator) retrievePackageInformation(dirPath string) (packageDataList []packageData) {
+/* Parses the packages in a directory, with warnings for those that are ignored. */
+func (g *AutoRegistryGenerator) retrievePackageInformation(
+	dirPath string, importPaths *importPathResolver,
+) (packageDataList []packageData, warnings []string, err error) {
 	set := token.NewFileSet()
 
 	packages, err := parser.ParseDir(set, dirPath, nil, 0)
 
 	if err != nil {
-		panic(fmt.Sprintf("Could not retrieve packages for path: '%s', error: '%s'", dirPath, err.Error()))
+		return nil, nil, fmt.Errorf("Could not retrieve packages for path: '%s', error: '%s'", dirPath, err.Error())
 	}
 
 	for _, pkg := range packages {
@@ -128,15 +280,15 @@ func (g *AutoRegistryGenerator) retrievePackageInformation(dirPath string) (pack
 
 		dir, _ := filepath.Split(firstFilePath)
 
-		importPath, err := retrieveImportPath(dir)
+		importPath, err := importPaths.importPath(dir)
 
 		if err != nil {
-			fmt.Println(fmt.Sprintf("[Warning] - Unable to retrieve import path for: '%s', ignoring...", importPath))
+			warnings = append(warnings, err.Error()+", ignoring...")
 
 			continue
 		}
 
-		structs, interfaces, factories := g.parseTypesFromPackage(pkg)
+		structs, interfaces, factories := g.parseTypesFromPackage(pkg, importPath)
 
 		packageDataList = append(packageDataList, packageData{
 			PackageName: pkg.Name,
@@ -147,11 +299,11 @@ func (g *AutoRegistryGenerator) retrievePackageInformation(dirPath string) (pack
 		})
 	}
 
-	return packageDataList
+	return packageDataList, warnings, nil
 }
 
 /* Use the AST to parse out certain types from a given package. */
-func (g *AutoRegistryGenerator) parseTypesFromPackage(pkg *ast.Package) (
+func (g *AutoRegistryGenerator) parseTypesFromPackage(pkg *ast.Package, importPath string) (
 	structs []string, interfaces []string, factories []factory,
 ) {
 	for filePath, file := range pkg.Files {
@@ -169,11 +321,14 @@ func (g *AutoRegistryGenerator) parseTypesFromPackage(pkg *ast.Package) (
 			continue
 		}
 
+		imports := parseFileImports(file)
+
 		for _, fileDeclarations := range file.Decls {
 			if genDecl, isGenDecl := fileDeclarations.(*ast.GenDecl); isGenDecl {
 				for _, fileDeclaration := range genDecl.Specs {
 					if theType, isTypeSpec := fileDeclaration.(*ast.TypeSpec); isTypeSpec {
-						if !theType.Name.IsExported() {
+						/* Generic types can't be registered without being instantiated. */
+						if !theType.Name.IsExported() || theType.TypeParams != nil {
 							continue
 						}
 
@@ -196,48 +351,24 @@ func (g *AutoRegistryGenerator) parseTypesFromPackage(pkg *ast.Package) (
 					continue
 				}
 
-				/* There are no return values, so it's not a factory! */
-				if funcDecl.Type.Results == nil {
-					continue
-				}
-
 				/* Ignore all those New methods that are on pointer receivers... they're not factory functions. */
 				if funcDecl.Recv != nil {
 					continue
 				}
 
-				/* "New" convention function found, but no return type? Ignore it... */
-				if len(funcDecl.Type.Results.List) == 0 {
-					continue
-				}
-
-				/* If the method returns a type, it's a factory! Naively assumes a single return type in pos [0]. */
-				returnType := funcDecl.Type.Results.List[0].Type
-
-				var returnTypeName string
+				returnImportPath, returnTypeName, isFactory := factoryReturnType(funcDecl, imports, importPath)
 
-				/* For future, interfaces: returnType.(*ast.Ident).Obj.Decl.(*ast.TypeSpec).Type.(*ast.InterfaceType) */
-				if structOrInterfaceReturntype, isStructOrInterface := returnType.(*ast.Ident); isStructOrInterface {
-					returnTypeName = structOrInterfaceReturntype.Name
-				}
-
-				if pointerReturnType, isPointerReturnType := returnType.(*ast.StarExpr); isPointerReturnType {
-					if identType, isIdentType := pointerReturnType.X.(*ast.Ident); isIdentType {
-						returnTypeName = identType.Name
-					} else {
-						/* It  could be an *ast.SelectorExpr - not sure why I'd want this yet... */
-						continue
-					}
+				if !isFactory {
+					continue
 				}
 
-				// @todo what if this factory returns an object from a completely separate package? Is it added to the
-				// @todo registry as if it was for the current package??
-
 				factories = append(
 					factories,
 					factory{
-						MethodName: funcDecl.Name.Name,
-						ReturnType: returnTypeName,
+						MethodName:       funcDecl.Name.Name,
+						ReturnImportPath: returnImportPath,
+						ReturnType:       returnTypeName,
+						Arguments:        parseArgumentNames(funcDecl),
 					},
 				)
 			}
@@ -248,28 +379,207 @@ func (g *AutoRegistryGenerator) parseTypesFromPackage(pkg *ast.Package) (
 }
 
 /*
-Dear programming gods, please forgive me for what I am about to do.
+Finds the type a factory returns, with the import path of it's package, and whether the function is a factory at all.
 
-Note: If there are no go files in this directory go list will send "can't load package, unknown import path" to stderr.
+The first result can be a struct or interface, a pointer to one, a type from another package like *logrus.Logger, or an
+instantiated generic type like Box[int]. It can be followed by a cleanup function, an error, or both, in that order.
+Generic functions are not factories, as they can't be registered without being instantiated.
 */
-func retrieveImportPath(dirPath string) (string, error) {
-	goPath, err := exec.LookPath("go")
+func factoryReturnType(
+	funcDecl *ast.FuncDecl, imports map[string]string, importPath string,
+) (returnImportPath string, returnTypeName string, isFactory bool) {
+	if funcDecl.Type.TypeParams != nil || funcDecl.Type.Results == nil {
+		return "", "", false
+	}
 
-	if err != nil {
-		return "", errors.New("Unable to retrieve go executable path , error:" + err.Error())
+	var results []ast.Expr
+
+	/* Named results of the same type, like "(a, b *Server)", share a single field in the AST. */
+	for _, result := range funcDecl.Type.Results.List {
+		for i := 0; i < len(result.Names) || i == 0; i++ {
+			results = append(results, result.Type)
+		}
 	}
 
-	cmd := exec.Command(goPath, "list")
-	cmd.Dir = dirPath
-	bytes, err := cmd.Output()
+	if len(results) == 0 {
+		return "", "", false
+	}
 
-	if err != nil {
-		return "", errors.New(
-			"Unable to retrieve import path for directory, are you using go modules? Error: " + err.Error(),
-		)
+	trailing := results[1:]
+
+	if len(trailing) > 0 && isCleanupFunction(trailing[0]) {
+		trailing = trailing[1:]
+	}
+
+	if len(trailing) > 0 && isIdentifier(trailing[0], "error") {
+		trailing = trailing[1:]
+	}
+
+	if len(trailing) > 0 {
+		return "", "", false
+	}
+
+	returnType := results[0]
+
+	if pointerReturnType, isPointerReturnType := returnType.(*ast.StarExpr); isPointerReturnType {
+		returnType = pointerReturnType.X
+	}
+
+	return qualifiedTypeName(returnType, imports, importPath)
+}
+
+/*
+The import path and name of a type as it is named by reflection, which names generic instantiations with their type
+arguments qualified by their full import path, like "Box[github.com/x/pkg.Item]".
+*/
+func qualifiedTypeName(
+	expr ast.Expr, imports map[string]string, importPath string,
+) (typeImportPath string, typeName string, found bool) {
+	switch typeExpr := expr.(type) {
+	case *ast.Ident:
+		return importPath, typeExpr.Name, true
+	case *ast.SelectorExpr:
+		if pkgIdent, isIdent := typeExpr.X.(*ast.Ident); isIdent && imports[pkgIdent.Name] != "" {
+			return imports[pkgIdent.Name], typeExpr.Sel.Name, true
+		}
+	case *ast.IndexExpr:
+		return instantiatedTypeName(typeExpr.X, []ast.Expr{typeExpr.Index}, imports, importPath)
+	case *ast.IndexListExpr:
+		return instantiatedTypeName(typeExpr.X, typeExpr.Indices, imports, importPath)
+	}
+
+	return "", "", false
+}
+
+func instantiatedTypeName(
+	genericType ast.Expr, typeArgs []ast.Expr, imports map[string]string, importPath string,
+) (typeImportPath string, typeName string, found bool) {
+	typeImportPath, typeName, found = qualifiedTypeName(genericType, imports, importPath)
+
+	typeArgNames := make([]string, 0, len(typeArgs))
+
+	for _, typeArg := range typeArgs {
+		typeArgName, typeArgFound := typeArgumentName(typeArg, imports, importPath)
+
+		if !typeArgFound {
+			return "", "", false
+		}
+
+		typeArgNames = append(typeArgNames, typeArgName)
+	}
+
+	return typeImportPath, typeName + "[" + strings.Join(typeArgNames, ",") + "]", found
+}
+
+/* The name of a type argument, qualified with it's import path unless it is a predeclared type like int. */
+func typeArgumentName(expr ast.Expr, imports map[string]string, importPath string) (string, bool) {
+	switch typeExpr := expr.(type) {
+	case *ast.StarExpr:
+		name, found := typeArgumentName(typeExpr.X, imports, importPath)
+
+		return "*" + name, found
+	case *ast.ArrayType:
+		name, found := typeArgumentName(typeExpr.Elt, imports, importPath)
+
+		if typeExpr.Len != nil {
+			return "", false
+		}
+
+		return "[]" + name, found
+	case *ast.MapType:
+		key, keyFound := typeArgumentName(typeExpr.Key, imports, importPath)
+		value, valueFound := typeArgumentName(typeExpr.Value, imports, importPath)
+
+		return "map[" + key + "]" + value, keyFound && valueFound
+	case *ast.Ident:
+		if types.Universe.Lookup(typeExpr.Name) != nil {
+			return typeExpr.Name, true
+		}
 	}
 
-	return strings.TrimSuffix(string(bytes), "\n"), nil
+	typeImportPath, typeName, found := qualifiedTypeName(expr, imports, importPath)
+
+	return typeImportPath + "." + typeName, found
+}
+
+/* Whether a result is a cleanup function, "func()", returned after the object for shutting it down. */
+func isCleanupFunction(expr ast.Expr) bool {
+	funcType, isFunc := expr.(*ast.FuncType)
+
+	return isFunc && (funcType.Params == nil || len(funcType.Params.List) == 0) &&
+		(funcType.Results == nil || len(funcType.Results.List) == 0)
+}
+
+func isIdentifier(expr ast.Expr, name string) bool {
+	ident, isIdent := expr.(*ast.Ident)
+
+	return isIdent && ident.Name == name
+}
+
+/*
+The import paths of a file by the name they are referred to with. Without an alias, the name is guessed from the path,
+as the imported package isn't parsed: without a major version like "/v2" or ".v2", or a "go-" prefix.
+*/
+func parseFileImports(file *ast.File) map[string]string {
+	imports := make(map[string]string)
+
+	for _, importSpec := range file.Imports {
+		importPath := unquotePath(importSpec.Path.Value)
+
+		if importSpec.Name != nil {
+			imports[importSpec.Name.Name] = importPath
+
+			continue
+		}
+
+		elements := strings.Split(importPath, "/")
+		name := elements[len(elements)-1]
+
+		if isMajorVersion(name) && len(elements) > 1 {
+			name = elements[len(elements)-2]
+		}
+
+		if dot := strings.LastIndex(name, "."); dot >= 0 && isMajorVersion(name[dot+1:]) {
+			name = name[:dot]
+		}
+
+		imports[strings.TrimPrefix(name, "go-")] = importPath
+	}
+
+	return imports
+}
+
+/* Whether a path element is a major version, like "v2". */
+func isMajorVersion(element string) bool {
+	if len(element) < 2 || element[0] != 'v' {
+		return false
+	}
+
+	_, err := strconv.Atoi(element[1:])
+
+	return err == nil
+}
+
+/* Retrieve the argument names of a function in order. Unnamed arguments, like "func(int)", have an empty name. */
+func parseArgumentNames(funcDecl *ast.FuncDecl) (argumentNames []string) {
+	if funcDecl.Type.Params == nil {
+		return
+	}
+
+	for _, param := range funcDecl.Type.Params.List {
+		if len(param.Names) == 0 {
+			argumentNames = append(argumentNames, "")
+
+			continue
+		}
+
+		/* Grouped arguments, like "func(x, y int)", share a single field in the AST. */
+		for _, name := range param.Names {
+			argumentNames = append(argumentNames, name.Name)
+		}
+	}
+
+	return
 }
 
 /* Reports whether or not the given file exists. */
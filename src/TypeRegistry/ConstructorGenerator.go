@@ -0,0 +1,154 @@
+package TypeRegistry
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+/*
+The program that writes the constructors. The registry has to be compiled into it, as the constructors are generated
+from the same decisions as Make(), which needs the types themselves rather than their names.
+*/
+var constructorProgram = template.Must(template.New("").Parse(`package main
+
+import (
+	"fmt"
+	"github.com/j7mbo/goij"
+	"github.com/j7mbo/goij/src/TypeRegistry"
+	"os"
+	registry {{ printf "%q" .RegistryImportPath }}
+)
+
+func main() {
+	ij := Goij.NewInjector(TypeRegistry.New(registry.GetRegistry()), nil)
+{{ if .ConfigPath }}
+	config, err := os.Open({{ printf "%q" .ConfigPath }})
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if err := ij.LoadConfig(config); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+{{ end }}
+	options := Goij.ConstructorOptions{
+		Package:    {{ printf "%q" .Package }},
+		ImportPath: {{ printf "%q" .ImportPath }},
+		Roots:      []string{ {{- range .Roots }}{{ printf "%q" . }}, {{ end -}} },
+	}
+
+	if err := Goij.GenerateConstructors(ij, os.Stdout, options); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+`))
+
+/* An object that generates plain Go constructors for root types, using a generated registry and configuration. */
+type ConstructorGenerator struct{}
+
+/* Initialise a new instance of ConstructorGenerator. */
+func NewConstructorGenerator() ConstructorGenerator {
+	return ConstructorGenerator{}
+}
+
+/* The prefix of the directory the program is written to, which the go command and the registry generator ignore. */
+const constructorProgramDir = "_goij_constructors"
+
+/*
+Generate constructors for the given struct or interface names, the same way the injector would make them.
+
+The file argument is the file to write the constructors to, which should be in a different package to the registry.
+The registryDir option is the directory of the package with the generated registry, which must not be package main.
+The configPath option is a JSON document for LoadConfig() with the bindings and definitions to use, if not empty.
+
+The constructors are written by a program that is run with "go run", so the go command must be on the PATH. It is
+written to a directory starting with "_goij_constructors" within registryDir, so that it can import the registry, and
+is removed afterwards. If generating is interrupted, it is removed the next time instead.
+*/
+func (g *ConstructorGenerator) Generate(file string, registryDir string, configPath string, roots ...string) error {
+	goCommand, err := exec.LookPath("go")
+
+	if err != nil {
+		return fmt.Errorf("The go command is needed to generate constructors, but it isn't on the PATH: %s", err.Error())
+	}
+
+	registryImportPath, err := retrieveImportPath(registryDir)
+
+	if err != nil {
+		return fmt.Errorf(
+			"Could not retrieve the import path of the registry in: '%s', error: %s", registryDir, err.Error(),
+		)
+	}
+
+	dir, _ := filepath.Split(file)
+
+	packageName := filepath.Base(dir)
+	importPath, err := retrieveImportPath(dir)
+
+	if err == nil {
+		packageName = importPath[strings.LastIndex(importPath, "/")+1:]
+	}
+
+	if configPath != "" {
+		if configPath, err = filepath.Abs(configPath); err != nil {
+			return fmt.Errorf("Could not retrieve absolute filepath for config: '%s', error: %s", configPath, err.Error())
+		}
+	}
+
+	/* Left behind by an interrupted run. */
+	leftovers, _ := filepath.Glob(filepath.Join(registryDir, constructorProgramDir+"*"))
+
+	for _, leftover := range leftovers {
+		_ = os.RemoveAll(leftover)
+	}
+
+	/* The program is written within the registry's module, so that it can import the registry. */
+	programDir, err := os.MkdirTemp(registryDir, constructorProgramDir)
+
+	if err != nil {
+		return fmt.Errorf("Unable to create a directory for the constructor program, error: %s", err.Error())
+	}
+
+	defer func() { _ = os.RemoveAll(programDir) }()
+
+	var program bytes.Buffer
+
+	err = constructorProgram.Execute(&program, struct {
+		RegistryImportPath, ConfigPath, Package, ImportPath string
+		Roots                                               []string
+	}{registryImportPath, configPath, packageName, importPath, roots})
+
+	if err == nil {
+		err = os.WriteFile(filepath.Join(programDir, "main.go"), program.Bytes(), 0644)
+	}
+
+	if err != nil {
+		return fmt.Errorf("Unable to write the constructor program, error: %s", err.Error())
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command(goCommand, "run", ".")
+	cmd.Dir = programDir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("Unable to generate constructors, error: %s\n%s", err.Error(), stderr.String())
+	}
+
+	if err := os.WriteFile(file, stdout.Bytes(), 0644); err != nil {
+		return fmt.Errorf("Unable to write constructors to file: %s, error: %s", file, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,259 @@
+package TypeRegistry
+
+import (
+	"errors"
+	"fmt"
+	"go/build"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*
+Maps directories to their import paths by reading the go.mod of the module they are in, the same way as go list but
+without running it for every directory. Directories outside of a module are mapped from GOPATH instead.
+*/
+type importPathResolver struct {
+	/* The module path of each directory with a go.mod, and "" for those without, so each is only read once. */
+	modules map[string]string
+
+	/* The directories of the modules used by each go.work, by it's path. */
+	workspaces map[string][]string
+
+	gopath []string
+
+	/* Directories are read in parallel, and share the modules and workspaces read so far. */
+	mutex sync.Mutex
+}
+
+func newImportPathResolver() *importPathResolver {
+	return &importPathResolver{
+		modules:    make(map[string]string),
+		workspaces: make(map[string][]string),
+		gopath:     filepath.SplitList(build.Default.GOPATH),
+	}
+}
+
+/* Retrieves the import path for a single directory. Use an importPathResolver for many, so go.mod is only read once. */
+func retrieveImportPath(dirPath string) (string, error) {
+	return newImportPathResolver().importPath(dirPath)
+}
+
+/* The import path of the package in a directory, which doesn't need to have any Go files in it yet. */
+func (r *importPathResolver) importPath(dirPath string) (string, error) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	dirPath, err := filepath.Abs(dirPath)
+
+	if err != nil {
+		return "", fmt.Errorf("Unable to retrieve absolute path for directory: '%s', error: %s", dirPath, err.Error())
+	}
+
+	moduleDir, modulePath, err := r.findModule(dirPath)
+
+	if err != nil {
+		return "", err
+	}
+
+	if moduleDir == "" {
+		return r.gopathImportPath(dirPath)
+	}
+
+	if err := r.checkWorkspace(dirPath, moduleDir); err != nil {
+		return "", err
+	}
+
+	relativePath, err := filepath.Rel(moduleDir, dirPath)
+
+	if err != nil || relativePath == "." {
+		return modulePath, err
+	}
+
+	return modulePath + "/" + filepath.ToSlash(relativePath), nil
+}
+
+/* Finds the nearest go.mod at or above a directory, returning it's directory and module path. */
+func (r *importPathResolver) findModule(dirPath string) (moduleDir string, modulePath string, err error) {
+	for dir := dirPath; ; dir = filepath.Dir(dir) {
+		modulePath, found := r.modules[dir]
+
+		if !found {
+			if modulePath, err = readModulePath(filepath.Join(dir, "go.mod")); err != nil {
+				return "", "", err
+			}
+
+			r.modules[dir] = modulePath
+		}
+
+		if modulePath != "" {
+			return dir, modulePath, nil
+		}
+
+		if filepath.Dir(dir) == dir {
+			return "", "", nil
+		}
+	}
+}
+
+/*
+Checks that the module of a directory is used by the go.work it is in, if there is one, as go list would fail otherwise.
+GOWORK is respected as it is by the go command: "off" disables workspaces, and a path is used instead of searching.
+*/
+func (r *importPathResolver) checkWorkspace(dirPath string, moduleDir string) error {
+	workFile := os.Getenv("GOWORK")
+
+	if workFile == "off" {
+		return nil
+	}
+
+	if workFile == "" {
+		for dir := dirPath; ; dir = filepath.Dir(dir) {
+			if fileExists(filepath.Join(dir, "go.work")) {
+				workFile = filepath.Join(dir, "go.work")
+
+				break
+			}
+
+			if filepath.Dir(dir) == dir {
+				return nil
+			}
+		}
+	}
+
+	moduleDirs, found := r.workspaces[workFile]
+
+	if !found {
+		var err error
+
+		if moduleDirs, err = readWorkspaceModules(workFile); err != nil {
+			return err
+		}
+
+		r.workspaces[workFile] = moduleDirs
+	}
+
+	for _, usedDir := range moduleDirs {
+		if usedDir == moduleDir {
+			return nil
+		}
+	}
+
+	return fmt.Errorf(
+		"Unable to retrieve import path for directory: '%s', it's module in: '%s' is not used by: '%s'",
+		dirPath, moduleDir, workFile,
+	)
+}
+
+/* The import path of a directory within the src directory of a GOPATH entry. */
+func (r *importPathResolver) gopathImportPath(dirPath string) (string, error) {
+	for _, gopath := range r.gopath {
+		relativePath, err := filepath.Rel(filepath.Join(gopath, "src"), dirPath)
+
+		if err == nil && relativePath != "." && !strings.HasPrefix(relativePath, "..") {
+			return filepath.ToSlash(relativePath), nil
+		}
+	}
+
+	return "", errors.New(
+		"Unable to retrieve import path for directory: '" + dirPath + "', it is not in a module or in GOPATH",
+	)
+}
+
+/* Reads the module path from a go.mod file, or "" if the file does not exist. */
+func readModulePath(goModPath string) (string, error) {
+	contents, err := os.ReadFile(goModPath)
+
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("Unable to read: '%s', error: %s", goModPath, err.Error())
+	}
+
+	lines := strings.Split(string(contents), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		fields := strings.Fields(stripComment(lines[i]))
+
+		if len(fields) == 0 || fields[0] != "module" {
+			continue
+		}
+
+		/* The module directive can also be a block, like "module (" with the path on the next line. */
+		if len(fields) == 2 && fields[1] == "(" && i+1 < len(lines) {
+			fields = append([]string{"module"}, strings.Fields(stripComment(lines[i+1]))...)
+		}
+
+		if len(fields) >= 2 {
+			return unquotePath(fields[1]), nil
+		}
+	}
+
+	return "", fmt.Errorf("Unable to find the module path in: '%s'", goModPath)
+}
+
+/* Reads the absolute directories of the modules in the use directives of a go.work file. */
+func readWorkspaceModules(goWorkPath string) (moduleDirs []string, err error) {
+	contents, err := os.ReadFile(goWorkPath)
+
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read: '%s', error: %s", goWorkPath, err.Error())
+	}
+
+	workDir := filepath.Dir(goWorkPath)
+	inUseBlock := false
+
+	for _, line := range strings.Split(string(contents), "\n") {
+		fields := strings.Fields(stripComment(line))
+
+		switch {
+		case len(fields) == 0:
+			continue
+		case inUseBlock && fields[0] == ")":
+			inUseBlock = false
+
+			continue
+		case fields[0] == "use" && len(fields) >= 2 && fields[1] == "(":
+			inUseBlock = true
+
+			continue
+		case fields[0] == "use" && len(fields) >= 2:
+			fields = fields[1:]
+		case !inUseBlock:
+			continue
+		}
+
+		moduleDir := filepath.FromSlash(unquotePath(fields[0]))
+
+		if !filepath.IsAbs(moduleDir) {
+			moduleDir = filepath.Join(workDir, moduleDir)
+		}
+
+		moduleDirs = append(moduleDirs, filepath.Clean(moduleDir))
+	}
+
+	return moduleDirs, nil
+}
+
+/* Removes a "//" comment from a line of a go.mod or go.work file. */
+func stripComment(line string) string {
+	if i := strings.Index(line, "//"); i >= 0 {
+		return line[:i]
+	}
+
+	return line
+}
+
+/* Paths in go.mod and go.work files may be quoted. */
+func unquotePath(path string) string {
+	if unquoted, err := strconv.Unquote(path); err == nil {
+		return unquoted
+	}
+
+	return path
+}
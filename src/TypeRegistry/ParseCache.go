@@ -0,0 +1,271 @@
+package TypeRegistry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+/* The version of the cache file, which is increased whenever what is parsed from a package changes. */
+const parseCacheVersion = 2
+
+/* Progress is how far GenerateTo() has got reading the directories, given to Options.Progress after each one. */
+type Progress struct {
+	/* The directories read so far, and how many there are in total. */
+	Read  int
+	Total int
+
+	/* How many of the directories read were parsed, and how many were unchanged and taken from the cache. */
+	Parsed int
+	Cached int
+
+	/* The time since the directories started being read. */
+	Elapsed time.Duration
+}
+
+/*
+What was parsed from the Go files in each directory, kept in the file given as Options.CacheFile so that only the
+directories that changed are parsed again next time.
+*/
+type parseCache struct {
+	Version     int                            `json:"version"`
+	Directories map[string]parseCacheDirectory `json:"directories"`
+}
+
+type parseCacheDirectory struct {
+	/* A hash of the import path of the directory, and the names and contents of it's Go files. */
+	Hash string `json:"hash"`
+
+	Packages []packageData `json:"packages"`
+
+	/* The warnings from parsing the directory, which are given again when it is taken from the cache. */
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+/* Reads the cache file, which is ignored if it doesn't exist yet, can't be read or is from another version. */
+func loadParseCache(cacheFile string) *parseCache {
+	cache := &parseCache{Version: parseCacheVersion, Directories: make(map[string]parseCacheDirectory)}
+
+	if cacheFile == "" {
+		return cache
+	}
+
+	contents, err := os.ReadFile(cacheFile)
+
+	if err != nil {
+		return cache
+	}
+
+	var existing parseCache
+
+	if err := json.Unmarshal(contents, &existing); err != nil || existing.Version != parseCacheVersion {
+		return cache
+	}
+
+	if existing.Directories != nil {
+		cache.Directories = existing.Directories
+	}
+
+	return cache
+}
+
+/* Writes the cache file, with only the directories that were read, so that removed directories are forgotten. */
+func saveParseCache(cacheFile string, read map[string]parseCacheDirectory) error {
+	contents, err := json.Marshal(parseCache{Version: parseCacheVersion, Directories: read})
+
+	if err == nil {
+		err = os.WriteFile(cacheFile, contents, 0644)
+	}
+
+	if err != nil {
+		return fmt.Errorf("Unable to write the cache file: %s, error: %s", cacheFile, err.Error())
+	}
+
+	return nil
+}
+
+/* The result of reading a directory, which is kept in order as directories are read in parallel. */
+type directoryResult struct {
+	dirPath  string
+	entry    parseCacheDirectory
+	cached   bool
+	hashed   bool
+	err      error
+	packages []packageData
+}
+
+/*
+Reads the packages in each directory, in parallel, taking those that haven't changed from the cache kept by Watch() or
+the cache file if there is one. The cache is updated, and the cache file written again, afterwards.
+*/
+func (g *AutoRegistryGenerator) readPackages(
+	dirPaths []string, importPaths *importPathResolver, options Options,
+) ([]packageData, error) {
+	start := time.Now()
+	cache := options.parseCache
+	useCache := cache != nil || options.CacheFile != ""
+
+	if cache == nil {
+		cache = loadParseCache(options.CacheFile)
+	}
+
+	results := make([]directoryResult, len(dirPaths))
+	indexes := make(chan int)
+	finished := make(chan int)
+
+	var workers sync.WaitGroup
+
+	for worker := 0; worker < runtime.NumCPU(); worker++ {
+		workers.Add(1)
+
+		go func() {
+			defer workers.Done()
+
+			for i := range indexes {
+				results[i] = g.readDirectory(dirPaths[i], importPaths, cache, useCache)
+				finished <- i
+			}
+		}()
+	}
+
+	go func() {
+		for i := range dirPaths {
+			indexes <- i
+		}
+
+		close(indexes)
+		workers.Wait()
+		close(finished)
+	}()
+
+	progress := Progress{Total: len(dirPaths)}
+
+	for i := range finished {
+		progress.Read++
+
+		if results[i].cached {
+			progress.Cached++
+		} else if results[i].err == nil {
+			progress.Parsed++
+		}
+
+		if options.Progress != nil {
+			progress.Elapsed = time.Since(start)
+			options.Progress(progress)
+		}
+	}
+
+	var packageDataList []packageData
+
+	read := make(map[string]parseCacheDirectory)
+
+	for _, result := range results {
+		if result.err != nil {
+			return nil, result.err
+		}
+
+		packageDataList = append(packageDataList, result.packages...)
+
+		for _, warning := range result.entry.Warnings {
+			options.warn("%s", warning)
+		}
+
+		if result.hashed {
+			read[result.dirPath] = result.entry
+		}
+	}
+
+	if options.parseCache != nil {
+		options.parseCache.Directories = read
+	}
+
+	if options.CacheFile == "" {
+		return packageDataList, nil
+	}
+
+	return packageDataList, saveParseCache(options.CacheFile, read)
+}
+
+/* Reads the packages in a directory from the cache if it's files haven't changed, or parses them if they have. */
+func (g *AutoRegistryGenerator) readDirectory(
+	dirPath string, importPaths *importPathResolver, cache *parseCache, useCache bool,
+) (result directoryResult) {
+	result.dirPath = dirPath
+
+	if useCache {
+		if absolutePath, err := filepath.Abs(dirPath); err == nil {
+			result.dirPath = absolutePath
+		}
+
+		result.entry.Hash, result.hashed = hashDirectory(dirPath, importPaths)
+	}
+
+	if cached, found := cache.Directories[result.dirPath]; result.hashed && found && cached.Hash == result.entry.Hash {
+		result.entry, result.packages, result.cached = cached, cached.Packages, true
+
+		return result
+	}
+
+	result.packages, result.entry.Warnings, result.err = g.retrievePackageInformation(dirPath, importPaths)
+	result.entry.Packages = result.packages
+
+	return result
+}
+
+/*
+Hashes the import path of a directory with the names and contents of it's Go files, which is everything that what is
+parsed from it depends on. Directories that can't be hashed, like those outside of a module, are always parsed.
+*/
+func hashDirectory(dirPath string, importPaths *importPathResolver) (string, bool) {
+	entries, err := os.ReadDir(dirPath)
+
+	if err != nil {
+		return "", false
+	}
+
+	var fileNames []string
+
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".go") {
+			fileNames = append(fileNames, entry.Name())
+		}
+	}
+
+	/* Directories without Go files have no packages, whatever their import path is. */
+	if len(fileNames) == 0 {
+		return "", true
+	}
+
+	importPath, err := importPaths.importPath(dirPath)
+
+	if err != nil {
+		return "", false
+	}
+
+	sort.Strings(fileNames)
+
+	hash := sha256.New()
+
+	fmt.Fprintf(hash, "%s\x00", importPath)
+
+	for _, fileName := range fileNames {
+		contents, err := os.ReadFile(filepath.Join(dirPath, fileName))
+
+		if err != nil {
+			return "", false
+		}
+
+		fmt.Fprintf(hash, "%s\x00%d\x00", fileName, len(contents))
+		hash.Write(contents)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), true
+}
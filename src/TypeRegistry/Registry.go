@@ -13,8 +13,15 @@ type RegistryFactory struct {
 	Implementations []interface{}
 }
 
+/* The argument names of a factory function, which cannot be retrieved with reflection. */
+type RegistryFactoryArguments struct {
+	Name      string
+	Arguments []string
+}
+
 type Registry struct {
-	RegistryStructs    []RegistryStruct
-	RegistryFactories  []RegistryFactory
-	RegistryInterfaces []RegistryInterface
+	RegistryStructs          []RegistryStruct
+	RegistryFactories        []RegistryFactory
+	RegistryInterfaces       []RegistryInterface
+	RegistryFactoryArguments []RegistryFactoryArguments
 }
@@ -0,0 +1,269 @@
+package TypeRegistry
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/* RegistryEntry is a struct, interface, factory or the argument names of a factory in a generated registry. */
+type RegistryEntry struct {
+	/* One of "struct", "interface", "factory" or "factory arguments". */
+	Kind string
+
+	/*
+		The fully qualified name, like "github.com/me/app/service.Server". Factories are the type they make followed by
+		the function, like "github.com/me/app/service.Server <- github.com/me/app/service.NewServer", and arguments are
+		the function followed by their names, like "github.com/me/app/service.NewServer(port, logger)".
+	*/
+	Name string
+}
+
+/* RegistryDifference is what a registry file is missing, and what it has that it shouldn't, compared to a new one. */
+type RegistryDifference struct {
+	Added   []RegistryEntry
+	Removed []RegistryEntry
+}
+
+/* Whether any struct, interface or factory was added or removed, rather than only the formatting or declaration. */
+func (d *RegistryDifference) HasEntries() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0
+}
+
+/* Lists the entries to add with a "+" and those to remove with a "-", like a diff. */
+func (d *RegistryDifference) String() string {
+	if !d.HasEntries() {
+		return "  The types are the same, but the package, function, build tags or formatting are different\n"
+	}
+
+	var lines strings.Builder
+
+	for _, entry := range d.Added {
+		fmt.Fprintf(&lines, "  + %s %s\n", entry.Kind, entry.Name)
+	}
+
+	for _, entry := range d.Removed {
+		fmt.Fprintf(&lines, "  - %s %s\n", entry.Kind, entry.Name)
+	}
+
+	return lines.String()
+}
+
+/*
+Check regenerates the registry in memory and compares it with the file, guessing the package name from it's directory if
+it isn't an option, the same as GenerateFile(). It returns nil if the file is up to date, or what has changed if not.
+*/
+func (g *AutoRegistryGenerator) Check(file string, options Options) (*RegistryDifference, error) {
+	if options.PackageName == "" {
+		options.PackageName = guessPackageName(file, options)
+	}
+
+	existing, err := os.ReadFile(file)
+
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read the registry to check: %s, error: %s", file, err.Error())
+	}
+
+	var generated bytes.Buffer
+
+	if err := g.GenerateTo(&generated, options); err != nil {
+		return nil, err
+	}
+
+	if bytes.Equal(existing, generated.Bytes()) {
+		return nil, nil
+	}
+
+	return CompareRegistries(existing, generated.Bytes())
+}
+
+/* CompareRegistries lists the entries of the generated registry missing from the existing one, and the reverse. */
+func CompareRegistries(existing []byte, generated []byte) (*RegistryDifference, error) {
+	existingEntries, err := parseRegistryEntries(existing)
+
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse the existing registry, error: %s", err.Error())
+	}
+
+	generatedEntries, err := parseRegistryEntries(generated)
+
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse the generated registry, error: %s", err.Error())
+	}
+
+	difference := &RegistryDifference{}
+
+	for _, entry := range sortedEntries(generatedEntries) {
+		if !existingEntries[entry] {
+			difference.Added = append(difference.Added, entry)
+		}
+	}
+
+	for _, entry := range sortedEntries(existingEntries) {
+		if !generatedEntries[entry] {
+			difference.Removed = append(difference.Removed, entry)
+		}
+	}
+
+	return difference, nil
+}
+
+/*
+Reads the entries from the composite literals of a generated registry. Functions and types are named by the import path
+of their package rather than it's alias, so that registries with different aliases can be compared.
+*/
+func parseRegistryEntries(source []byte) (map[RegistryEntry]bool, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", source, 0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := parseFileImports(file)
+	entries := make(map[RegistryEntry]bool)
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		literal, isLiteral := node.(*ast.CompositeLit)
+
+		if !isLiteral {
+			return true
+		}
+
+		selector, isSelector := literal.Type.(*ast.SelectorExpr)
+
+		if !isSelector {
+			return true
+		}
+
+		fields := compositeLiteralFields(literal)
+		name, _ := stringLiteral(fields["Name"])
+
+		switch selector.Sel.Name {
+		case "RegistryStruct":
+			entries[RegistryEntry{Kind: "struct", Name: name}] = true
+		case "RegistryInterface":
+			entries[RegistryEntry{Kind: "interface", Name: name}] = true
+		case "RegistryFactory":
+			implementations, _ := fields["Implementations"].(*ast.CompositeLit)
+
+			if implementations == nil {
+				return false
+			}
+
+			for _, implementation := range implementations.Elts {
+				function := qualifiedFunctionName(implementation, aliases)
+
+				entries[RegistryEntry{Kind: "factory", Name: name + " <- " + function}] = true
+			}
+		case "RegistryFactoryArguments":
+			var arguments []string
+
+			if argumentList, isList := fields["Arguments"].(*ast.CompositeLit); isList {
+				for _, argument := range argumentList.Elts {
+					argumentName, _ := stringLiteral(argument)
+					arguments = append(arguments, argumentName)
+				}
+			}
+
+			entries[RegistryEntry{
+				Kind: "factory arguments", Name: name + "(" + strings.Join(arguments, ", ") + ")",
+			}] = true
+		default:
+			return true
+		}
+
+		return false
+	})
+
+	return entries, nil
+}
+
+/* The values of the keyed fields of a composite literal, by key. */
+func compositeLiteralFields(literal *ast.CompositeLit) map[string]ast.Expr {
+	fields := make(map[string]ast.Expr)
+
+	for _, element := range literal.Elts {
+		keyValue, isKeyValue := element.(*ast.KeyValueExpr)
+
+		if !isKeyValue {
+			continue
+		}
+
+		if key, isIdent := keyValue.Key.(*ast.Ident); isIdent {
+			fields[key.Name] = keyValue.Value
+		}
+	}
+
+	return fields
+}
+
+func stringLiteral(expr ast.Expr) (string, bool) {
+	literal, isLiteral := expr.(*ast.BasicLit)
+
+	if !isLiteral || literal.Kind != token.STRING {
+		return "", false
+	}
+
+	value, err := strconv.Unquote(literal.Value)
+
+	return value, err == nil
+}
+
+/* The import path and name of a function like "service.NewServer", as the alias of the package can be anything. */
+func qualifiedFunctionName(expr ast.Expr, aliases map[string]string) string {
+	selector, isSelector := expr.(*ast.SelectorExpr)
+
+	if !isSelector {
+		return fmt.Sprintf("%T", expr)
+	}
+
+	alias, isIdent := selector.X.(*ast.Ident)
+
+	if !isIdent {
+		return selector.Sel.Name
+	}
+
+	if importPath, found := aliases[alias.Name]; found {
+		return importPath + "." + selector.Sel.Name
+	}
+
+	return alias.Name + "." + selector.Sel.Name
+}
+
+/* The order entries are listed in, the same as in a generated registry. */
+func entryKindOrder(kind string) int {
+	switch kind {
+	case "struct":
+		return 0
+	case "interface":
+		return 1
+	case "factory arguments":
+		return 2
+	}
+
+	return 3
+}
+
+func sortedEntries(entries map[RegistryEntry]bool) []RegistryEntry {
+	sorted := make([]RegistryEntry, 0, len(entries))
+
+	for entry := range entries {
+		sorted = append(sorted, entry)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Kind != sorted[j].Kind {
+			return entryKindOrder(sorted[i].Kind) < entryKindOrder(sorted[j].Kind)
+		}
+
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	return sorted
+}
@@ -16,6 +16,12 @@ type TypeRegistry struct {
 
 	/* Only contains factories. */
 	factoryRegistry map[string][]interface{}
+
+	/* Factory function names to their argument names. */
+	factoryArgumentRegistry map[string][]string
+
+	/* Incremented whenever types are added, so that anything found in the registry can be looked up again. */
+	version uint64
 }
 
 /* Terrible wizardry. You can pass the registry in created from having run ./bin/gen. */
@@ -23,6 +29,7 @@ func New(registries ...Registry) *TypeRegistry {
 	structRegistry := make(map[string]interface{})
 	interfaceRegistry := make(map[string]interface{})
 	factoryRegistry := make(map[string][]interface{})
+	factoryArgumentRegistry := make(map[string][]string)
 
 	for _, userRegistry := range registries {
 		for _, registryStruct := range userRegistry.RegistryStructs {
@@ -38,16 +45,23 @@ func New(registries ...Registry) *TypeRegistry {
 				factoryRegistry[registryFactory.Name] = append(factoryRegistry[registryFactory.Name], implementation)
 			}
 		}
+
+		for _, registryFactoryArguments := range userRegistry.RegistryFactoryArguments {
+			factoryArgumentRegistry[registryFactoryArguments.Name] = registryFactoryArguments.Arguments
+		}
 	}
 
 	return &TypeRegistry{
-		structRegistry:    structRegistry,
-		interfaceRegistry: interfaceRegistry,
-		factoryRegistry:   factoryRegistry,
+		structRegistry:          structRegistry,
+		interfaceRegistry:       interfaceRegistry,
+		factoryRegistry:         factoryRegistry,
+		factoryArgumentRegistry: factoryArgumentRegistry,
 	}
 }
 
 func (r *TypeRegistry) Add(registry Registry) {
+	r.version++
+
 	for _, registryStruct := range registry.RegistryStructs {
 		r.structRegistry[registryStruct.Name] = registryStruct.Implementation
 	}
@@ -61,6 +75,15 @@ func (r *TypeRegistry) Add(registry Registry) {
 			r.factoryRegistry[registryFactory.Name] = append(r.factoryRegistry[registryFactory.Name], implementation)
 		}
 	}
+
+	for _, registryFactoryArguments := range registry.RegistryFactoryArguments {
+		r.factoryArgumentRegistry[registryFactoryArguments.Name] = registryFactoryArguments.Arguments
+	}
+}
+
+/* Version changes whenever types are added to the registry. */
+func (r *TypeRegistry) Version() uint64 {
+	return r.version
 }
 
 func (r *TypeRegistry) FindStructType(name string) interface{} {
@@ -123,6 +146,17 @@ func (r *TypeRegistry) FindInterfaceType(name string) interface{} {
 	return nil
 }
 
+/* Returns a copy of every struct type in the registry, keyed by fully qualified name. */
+func (r *TypeRegistry) FindAllStructTypes() map[string]interface{} {
+	structs := make(map[string]interface{}, len(r.structRegistry))
+
+	for name, structType := range r.structRegistry {
+		structs[name] = structType
+	}
+
+	return structs
+}
+
 func (r *TypeRegistry) FindFactoryTypes(name string) []interface{} {
 	if theType, exists := r.factoryRegistry[name]; exists {
 		return theType
@@ -131,6 +165,15 @@ func (r *TypeRegistry) FindFactoryTypes(name string) []interface{} {
 	return nil
 }
 
+/* Given a fully qualified factory function name, return the names of it's arguments if they were registered. */
+func (r *TypeRegistry) FindFactoryArgumentNames(name string) []string {
+	if argumentNames, exists := r.factoryArgumentRegistry[name]; exists {
+		return argumentNames
+	}
+
+	return nil
+}
+
 /* Given a reflect value (when recursing around a struct's fields with reflect); find the object already stored. */
 func (r *TypeRegistry) FindInterfaceTypeByType(objType reflect.Type) interface{} {
 	if objType.Kind() == reflect.Ptr {
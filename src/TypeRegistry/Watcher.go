@@ -0,0 +1,255 @@
+package TypeRegistry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+/* WatchOptions are how often Watch() looks for changes, and how long it waits for them to stop before regenerating. */
+type WatchOptions struct {
+	/* How often the directories are polled for changes to .go files, 500ms if zero. */
+	Interval time.Duration
+
+	/* How long to wait for changes to stop before regenerating, so a burst of saves regenerates once. 300ms if zero. */
+	Debounce time.Duration
+}
+
+/* WatchEvent is the result of regenerating the registry after .go files changed. */
+type WatchEvent struct {
+	/* The .go files that were added, changed or removed, relative to the watched directory. */
+	Files []string
+
+	/* What was added to and removed from the registry, which has no entries if only it's formatting changed. */
+	Difference *RegistryDifference
+
+	/* Whether the file was written, which it is whenever the generated registry is different to it. */
+	Written bool
+
+	/*
+		Why the registry couldn't be generated, or the directories couldn't be read, in which case the registry is left
+		as it was and will be tried again.
+	*/
+	Err error
+}
+
+/* A short summary of what changed, like "Changed: service/Server.go, the registry was updated:" and the difference. */
+func (e WatchEvent) String() string {
+	files := strings.Join(e.Files, ", ")
+
+	/* The registry is generated once when watching starts, before any files have changed. */
+	if len(e.Files) == 0 {
+		files = "nothing yet"
+	}
+
+	switch {
+	case e.Err != nil && len(e.Files) == 0:
+		return fmt.Sprintf("Unable to generate the registry: %s\n", e.Err.Error())
+	case e.Err != nil:
+		return fmt.Sprintf("Changed: %s, unable to generate the registry: %s\n", files, e.Err.Error())
+	case e.Difference.HasEntries():
+		return fmt.Sprintf("Changed: %s, the registry was updated:\n%s", files, e.Difference)
+	case e.Written:
+		return fmt.Sprintf("Changed: %s, the registry was rewritten:\n%s", files, e.Difference)
+	}
+
+	return fmt.Sprintf("Changed: %s, the registry is up to date\n", files)
+}
+
+/* The modification time and size of a .go file, which change when it is saved. */
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+/*
+Watch generates the registry to the file, then polls options.Dir for .go files that are added, changed or removed, and
+regenerates the registry once they stop changing. The file is only written if the registry is different, and report is
+called with what changed each time. Directories in options.Exclude and the file itself are not watched.
+
+What was parsed from each directory is kept between regenerations, so only the directories that changed are parsed
+again, as with options.CacheFile, which is also read to start with and written after each regeneration if given.
+
+It returns when the context is done, or with an error if the directories can't be read to start with. Once watching,
+directories that can't be read are reported as an event with an error, and are read again on the next poll.
+*/
+func (g *AutoRegistryGenerator) Watch(
+	ctx context.Context, file string, options Options, watchOptions WatchOptions, report func(WatchEvent),
+) error {
+	if watchOptions.Interval <= 0 {
+		watchOptions.Interval = 500 * time.Millisecond
+	}
+
+	if watchOptions.Debounce <= 0 {
+		watchOptions.Debounce = 300 * time.Millisecond
+	}
+
+	if options.PackageName == "" {
+		options.PackageName = guessPackageName(file, options)
+	}
+
+	/* The registry is written within the directory, which shouldn't be seen as a change. */
+	absoluteFile, err := filepath.Abs(file)
+
+	if err != nil {
+		return fmt.Errorf("Unable to retrieve absolute path for file: '%s', error: %s", file, err.Error())
+	}
+
+	file = absoluteFile
+	options.parseCache = loadParseCache(options.CacheFile)
+
+	stamps, err := stampGoFiles(options.Dir, options.Exclude, file)
+
+	if err != nil {
+		return err
+	}
+
+	report(g.regenerate(file, options, nil))
+
+	ticker := time.NewTicker(watchOptions.Interval)
+
+	defer ticker.Stop()
+
+	changed := make(map[string]bool)
+
+	var lastChange time.Time
+
+	/* The last error reading the directories, which is only reported again if it changes. */
+	var lastErr string
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			current, err := stampGoFiles(options.Dir, options.Exclude, file)
+
+			if err != nil {
+				if err.Error() != lastErr {
+					report(WatchEvent{Difference: &RegistryDifference{}, Err: err})
+				}
+
+				lastErr = err.Error()
+
+				continue
+			}
+
+			lastErr = ""
+
+			if files := changedFiles(stamps, current); len(files) > 0 {
+				for _, changedFile := range files {
+					changed[changedFile] = true
+				}
+
+				stamps, lastChange = current, now
+
+				continue
+			}
+
+			if len(changed) > 0 && now.Sub(lastChange) >= watchOptions.Debounce {
+				report(g.regenerate(file, options, changed))
+
+				changed = make(map[string]bool)
+			}
+		}
+	}
+}
+
+/* Generates the registry and writes it to the file if it is different, returning what changed. */
+func (g *AutoRegistryGenerator) regenerate(file string, options Options, changed map[string]bool) WatchEvent {
+	event := WatchEvent{Difference: &RegistryDifference{}}
+
+	for changedFile := range changed {
+		if relativePath, err := filepath.Rel(options.Dir, changedFile); err == nil {
+			changedFile = relativePath
+		}
+
+		event.Files = append(event.Files, changedFile)
+	}
+
+	sort.Strings(event.Files)
+
+	var generated bytes.Buffer
+
+	if event.Err = g.GenerateTo(&generated, options); event.Err != nil {
+		return event
+	}
+
+	existing, err := os.ReadFile(file)
+
+	if err == nil && bytes.Equal(existing, generated.Bytes()) {
+		return event
+	}
+
+	/* A missing or broken registry is compared as an empty one, so that everything in the new one is listed. */
+	if event.Difference, err = CompareRegistries(existing, generated.Bytes()); err != nil {
+		event.Difference, _ = CompareRegistries([]byte("package "+options.PackageName), generated.Bytes())
+	}
+
+	if err := os.WriteFile(file, generated.Bytes(), 0644); err != nil {
+		event.Err = fmt.Errorf("Unable to write the registry to file: %s, error: %s", file, err.Error())
+
+		return event
+	}
+
+	event.Written = true
+
+	return event
+}
+
+/* The stamps of the .go files within a directory, apart from those excluded and the registry file itself. */
+func stampGoFiles(dirPath string, ignoreDirs []string, registryFile string) (map[string]fileStamp, error) {
+	stamps := make(map[string]fileStamp)
+
+	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
+		switch {
+		/* Files and directories removed while walking are gone, rather than unreadable. */
+		case err != nil && os.IsNotExist(err) && path != dirPath:
+			return nil
+		case err != nil:
+			return err
+		case info.IsDir() && (isExcluded(path, ignoreDirs) || path != dirPath && ignoredByGo(info.Name())):
+			return filepath.SkipDir
+		case info.IsDir() || !strings.HasSuffix(path, ".go") || isExcluded(path, ignoreDirs):
+			return nil
+		}
+
+		if absolutePath, err := filepath.Abs(path); err == nil && absolutePath == registryFile {
+			return nil
+		}
+
+		stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("Unable to watch: '%s' for changes, error: %s", dirPath, err.Error())
+	}
+
+	return stamps, nil
+}
+
+/* The files that were added, changed or removed between two sets of stamps. */
+func changedFiles(previous map[string]fileStamp, current map[string]fileStamp) (files []string) {
+	for path, stamp := range current {
+		previousStamp, found := previous[path]
+
+		if !found || !previousStamp.modTime.Equal(stamp.modTime) || previousStamp.size != stamp.size {
+			files = append(files, path)
+		}
+	}
+
+	for path := range previous {
+		if _, found := current[path]; !found {
+			files = append(files, path)
+		}
+	}
+
+	return files
+}
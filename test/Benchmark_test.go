@@ -0,0 +1,123 @@
+package test
+
+import (
+	"github.com/j7mbo/goij"
+	"github.com/j7mbo/goij/src/TypeRegistry"
+	"testing"
+)
+
+/* A graph six structs deep, each with an interface, a definition and a scalar, made by a factory so it isn't cached. */
+type deepService interface{ Serve() }
+type deepServiceImpl struct{ Name string }
+type deepGraph struct{ Level *deepLevel0 }
+type deepLevel0 struct {
+	Next    *deepLevel1
+	Service deepService
+	Name    string
+	Count   int
+}
+type deepLevel1 struct {
+	Next    *deepLevel2
+	Service deepService
+	Name    string
+	Count   int
+}
+type deepLevel2 struct {
+	Next    deepLevel3
+	Service deepService
+	Name    string
+	Count   int
+}
+type deepLevel3 struct {
+	Next    *deepLevel4
+	Service deepService
+	Name    string
+	Count   int
+}
+type deepLevel4 struct {
+	Next    deepLevel5
+	Service deepService
+	Name    string
+	Count   int
+}
+type deepLevel5 struct {
+	Service deepService
+	Name    string
+	Count   int
+}
+
+func (deepServiceImpl) Serve() {}
+
+func NewDeepGraph(level *deepLevel0) deepGraph {
+	return deepGraph{Level: level}
+}
+
+func deepGraphRegistry() TypeRegistry.Registry {
+	return TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.deepServiceImpl", Implementation: deepServiceImpl{}},
+			{Name: "github.com/j7mbo/goij/test.deepGraph", Implementation: deepGraph{}},
+			{Name: "github.com/j7mbo/goij/test.deepLevel0", Implementation: deepLevel0{}},
+			{Name: "github.com/j7mbo/goij/test.deepLevel1", Implementation: deepLevel1{}},
+			{Name: "github.com/j7mbo/goij/test.deepLevel2", Implementation: deepLevel2{}},
+			{Name: "github.com/j7mbo/goij/test.deepLevel3", Implementation: deepLevel3{}},
+			{Name: "github.com/j7mbo/goij/test.deepLevel4", Implementation: deepLevel4{}},
+			{Name: "github.com/j7mbo/goij/test.deepLevel5", Implementation: deepLevel5{}},
+		},
+		RegistryInterfaces: []TypeRegistry.RegistryInterface{
+			{Name: "github.com/j7mbo/goij/test.deepService", Implementation: (*deepService)(nil)},
+		},
+		RegistryFactories: []TypeRegistry.RegistryFactory{
+			{Name: "github.com/j7mbo/goij/test.deepGraph", Implementations: []interface{}{NewDeepGraph}},
+		},
+	}
+}
+
+func newDeepGraphInjector() Goij.Injector {
+	ij := Goij.NewInjector(TypeRegistry.New(deepGraphRegistry()), nil)
+	ij.DefineGlobal("Name", "deep")
+
+	return ij
+}
+
+/* The whole graph is resolved on every Make(), as factory results are not cached. */
+func BenchmarkMakeDeepGraph(b *testing.B) {
+	ij := newDeepGraphInjector()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		ij.Make("deepGraph")
+	}
+}
+
+/* Includes resolving everything for the first time with a new injector. */
+func BenchmarkMakeDeepGraphWithNewInjector(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		newDeepGraphInjector().Make("deepGraph")
+	}
+}
+
+/* The top level struct is cached after the first Make(), so this is a copy of the cached object. */
+func BenchmarkMakeCachedDeepLevel(b *testing.B) {
+	ij := newDeepGraphInjector()
+	ij.Make("deepLevel0")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		ij.Make("deepLevel0")
+	}
+}
+
+/* Asserts the benchmarked graph is fully resolved, so that the benchmarks measure something real. */
+func (s *InjectorTestSuite) TestDeepGraphIsResolved() {
+	graph := newDeepGraphInjector().Make("deepGraph").(deepGraph)
+
+	s.Assert().Equal("deep", graph.Level.Next.Next.Next.Next.Next.Name)
+	s.Assert().Equal(&deepServiceImpl{Name: "deep"}, graph.Level.Next.Next.Next.Next.Next.Service)
+}
@@ -1,14 +1,33 @@
 package test
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/j7mbo/MethodCallRetrier"
 	"github.com/j7mbo/goij"
 	"github.com/j7mbo/goij/src/Logger"
 	"github.com/j7mbo/goij/src/TypeRegistry"
+	"github.com/j7mbo/goij/test/fixtures"
+	"github.com/j7mbo/goij/test/fixtures/constructors"
+	fixturesregistry "github.com/j7mbo/goij/test/fixtures/registry"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/suite"
+	"go/format"
+	"io"
+	"log/slog"
 	"math/rand"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 type InjectorTestSuite struct {
@@ -1349,6 +1368,2110 @@ func (s *InjectorTestSuite) TestSharedDependencyUsedInDelegate() {
 	s.Equal(128, ij.Make("github.com/j7mbo/goij/test.ParentObjForObjWithSharedDep").(*ParentObjForObjWithSharedDep).ObjWithSharedDep.TestObjWithInt.Int)
 }
 
+func (s *InjectorTestSuite) TestCallResolvesFunctionArguments() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testDepForFactoryWithArgs", Implementation: testDepForFactoryWithArgs{}},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+	ij.Share(testDepForFactoryWithArgs{Int: 42})
+
+	results, err := ij.Call(func(dep *testDepForFactoryWithArgs) int {
+		return dep.Int
+	})
+
+	s.Assert().NoError(err)
+	s.Assert().Equal([]interface{}{42}, results)
+}
+
+func (s *InjectorTestSuite) TestCallUsesOverridesBeforeResolvingArguments() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementation: testObjWithInt{}},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+
+	results, err := ij.Call(func(multiplier int, obj testObjWithInt) int {
+		return multiplier * obj.Int
+	}, 2, testObjWithInt{Int: 21})
+
+	s.Assert().NoError(err)
+	s.Assert().Equal([]interface{}{42}, results)
+}
+
+func (s *InjectorTestSuite) TestCallReturnsTrailingErrorAsError() {
+	ij := Goij.NewInjector(TypeRegistry.New(), nil)
+
+	results, err := ij.Call(func() (int, error) {
+		return 42, errors.New("job failed")
+	})
+
+	s.Assert().EqualError(err, "job failed")
+	s.Assert().Equal([]interface{}{42}, results)
+}
+
+func (s *InjectorTestSuite) TestCallWithUnresolvableArgumentReturnsError() {
+	ij := Goij.NewInjector(TypeRegistry.New(), nil)
+
+	_, err := ij.Call(func(dep testInterface) {})
+
+	s.Assert().Error(err)
+}
+
+func (s *InjectorTestSuite) TestCallWithNonFunctionReturnsError() {
+	ij := Goij.NewInjector(TypeRegistry.New(), nil)
+
+	_, err := ij.Call(testObjWithInt{})
+
+	s.Assert().Error(err)
+}
+
+func (s *InjectorTestSuite) TestCanInvokePointerReceiverMethodOnValue() {
+	ij := Goij.NewInjector(TypeRegistry.New(), nil)
+
+	s.Assert().Equal(42, ij.Invoke(testObjWithInt{Int: 42}, "IntMethod")[0])
+}
+
+func (s *InjectorTestSuite) TestCanInvokeVariadicMethod() {
+	ij := Goij.NewInjector(TypeRegistry.New(), nil)
+
+	s.Assert().Equal(42, ij.Invoke(&testObjWithInt{}, "Sum", 40, 1, 1)[0])
+	s.Assert().Equal(0, ij.Invoke(&testObjWithInt{}, "Sum")[0])
+	s.Assert().Equal(42, ij.Invoke(&testObjWithInt{}, "Sum", []int{41, 1})[0])
+}
+
+func (s *InjectorTestSuite) TestCanInvokeMethodWithNilInterfaceArgument() {
+	ij := Goij.NewInjector(TypeRegistry.New(), nil)
+
+	s.Assert().Equal(true, ij.Invoke(&testObjWithInt{}, "IsNil", nil)[0])
+}
+
+func (s *InjectorTestSuite) TestInvokingMissingMethodPanicsWithInvocationError() {
+	ij := Goij.NewInjector(TypeRegistry.New(), nil)
+
+	s.Assert().EqualError(
+		recoverError(func() { ij.Invoke(&testObjWithInt{}, "DoesNotExist") }),
+		"Unable to invoke method: 'DoesNotExist' on type: '*test.testObjWithInt': no exported method exists with "+
+			"this name on either the value or pointer receiver",
+	)
+}
+
+func (s *InjectorTestSuite) TestInvokingWithWrongArgumentsPanicsWithInvocationError() {
+	ij := Goij.NewInjector(TypeRegistry.New(), nil)
+
+	s.Assert().EqualError(
+		recoverError(func() { ij.Invoke(&testObjWithInt{}, "IsNil") }),
+		"Unable to invoke method: 'IsNil' on type: '*test.testObjWithInt': expected 1 argument(s), got 0",
+	)
+
+	s.Assert().EqualError(
+		recoverError(func() { ij.Invoke(&testObjWithInt{}, "Sum", 1, "2") }),
+		"Unable to invoke method: 'Sum' on type: '*test.testObjWithInt', parameter 1: argument of type: string is "+
+			"not assignable to type: int",
+	)
+}
+
+func (s *InjectorTestSuite) TestEnvTaggedFieldsAreInjectedFromEnvironment() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithEnvTags", Implementation: testObjWithEnvTags{}},
+		},
+	}
+
+	s.Require().NoError(os.Setenv("GOIJ_TEST_HOST", "localhost"))
+	s.Require().NoError(os.Setenv("GOIJ_TEST_TIMEOUT", "5s"))
+	s.Require().NoError(os.Setenv("GOIJ_TEST_TAGS", "one, two"))
+
+	defer func() {
+		_ = os.Unsetenv("GOIJ_TEST_HOST")
+		_ = os.Unsetenv("GOIJ_TEST_TIMEOUT")
+		_ = os.Unsetenv("GOIJ_TEST_TAGS")
+	}()
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+
+	obj := ij.Make("testObjWithEnvTags").(*testObjWithEnvTags)
+
+	s.Assert().Equal("localhost", obj.Host)
+	s.Assert().Equal(8080, obj.Port)
+	s.Assert().Equal(5*time.Second, obj.Timeout)
+	s.Assert().Equal([]string{"one", "two"}, obj.Tags)
+}
+
+func (s *InjectorTestSuite) TestMissingRequiredEnvTaggedFieldPanics() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithEnvTags", Implementation: testObjWithEnvTags{}},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+
+	s.Assert().Panics(func() {
+		ij.Make("testObjWithEnvTags")
+	})
+}
+
+func (s *InjectorTestSuite) TestCanDefineFromEnvironmentWithPrefix() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementation: testObjWithInt{}},
+		},
+	}
+
+	s.Require().NoError(os.Setenv("GOIJ_TESTOBJWITHINT_INT", "42"))
+
+	defer func() { _ = os.Unsetenv("GOIJ_TESTOBJWITHINT_INT") }()
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+
+	s.Assert().NoError(ij.DefineFromEnv("GOIJ"))
+	s.Assert().Equal(42, ij.Make("testObjWithInt").(*testObjWithInt).Int)
+}
+
+func (s *InjectorTestSuite) TestDefineFromEnvironmentWithInvalidValueReturnsError() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementation: testObjWithInt{}},
+		},
+	}
+
+	s.Require().NoError(os.Setenv("GOIJ_TESTOBJWITHINT_INT", "forty-two"))
+
+	defer func() { _ = os.Unsetenv("GOIJ_TESTOBJWITHINT_INT") }()
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+
+	s.Assert().Error(ij.DefineFromEnv("GOIJ_"))
+}
+
+func (s *InjectorTestSuite) TestDefineFromEnvironmentErrorsAreInTheSameOrderEveryTime() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementation: testObjWithInt{}},
+			{Name: "github.com/j7mbo/goij/test.testDepForFactoryWithArgs", Implementation: testDepForFactoryWithArgs{}},
+		},
+	}
+
+	s.Require().NoError(os.Setenv("GOIJ_TESTOBJWITHINT_INT", "forty-two"))
+	s.Require().NoError(os.Setenv("GOIJ_TESTDEPFORFACTORYWITHARGS_INT", "forty-two"))
+
+	defer func() {
+		_ = os.Unsetenv("GOIJ_TESTOBJWITHINT_INT")
+		_ = os.Unsetenv("GOIJ_TESTDEPFORFACTORYWITHARGS_INT")
+	}()
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+
+	err := ij.DefineFromEnv("GOIJ")
+
+	s.Require().Error(err)
+
+	first := strings.Index(err.Error(), "GOIJ_TESTDEPFORFACTORYWITHARGS_INT")
+	second := strings.Index(err.Error(), "GOIJ_TESTOBJWITHINT_INT")
+
+	s.Assert().True(first >= 0 && first < second, err.Error())
+
+	for i := 0; i < 10; i++ {
+		s.Assert().Equal(err.Error(), ij.DefineFromEnv("GOIJ").Error())
+	}
+}
+
+func (s *InjectorTestSuite) TestEnvTagDefaultIsTheRestOfTheTag() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithEnvDefaults", Implementation: testObjWithEnvDefaults{}},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+
+	obj := ij.Make("testObjWithEnvDefaults").(*testObjWithEnvDefaults)
+
+	s.Assert().Equal([]string{"one", "two"}, obj.Tags)
+}
+
+func (s *InjectorTestSuite) TestCanLoadConfigurationFromJSON() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjToMake", Implementation: testObjToMake{}},
+			{Name: "github.com/j7mbo/goij/test.testObj", Implementation: testObj{}},
+			{Name: "github.com/j7mbo/goij/test.testObj2", Implementation: testObj2{}},
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementation: testObjWithInt{}},
+			{Name: "github.com/j7mbo/goij/test.testObjWithEnvTags", Implementation: testObjWithEnvTags{}},
+			{Name: "github.com/j7mbo/goij/test.testDepForFactoryWithArgs", Implementation: testDepForFactoryWithArgs{}},
+		},
+		RegistryInterfaces: []TypeRegistry.RegistryInterface{
+			{Name: "github.com/j7mbo/goij/test.testInterface", Implementation: (*testInterface)(nil)},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+
+	err := ij.LoadConfig(strings.NewReader(`{
+		"bindings": { "testInterface": "testObj2" },
+		"definitions": { "testObjWithEnvTags": { "Host": "localhost", "Timeout": "5s", "Tags": ["one"] } },
+		"globals": { "Port": 8080 },
+		"shared": { "testObjWithInt": { "Int": 42 } }
+	}`))
+
+	s.Require().NoError(err)
+
+	s.Assert().IsType(&testObj2{}, ij.Make("testObjToMake").(*testObjToMake).Dep)
+	s.Assert().Equal(42, ij.Make("testObjWithInt").(*testObjWithInt).Int)
+
+	obj := ij.Make("testObjWithEnvTags").(*testObjWithEnvTags)
+
+	s.Assert().Equal("localhost", obj.Host)
+	s.Assert().Equal(8080, obj.Port)
+	s.Assert().Equal(5*time.Second, obj.Timeout)
+	s.Assert().Equal([]string{"one"}, obj.Tags)
+}
+
+func (s *InjectorTestSuite) TestLoadingConfigurationWithUnknownNamesReportsJSONPaths() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementation: testObjWithInt{}},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+
+	err := ij.LoadConfig(strings.NewReader(`{
+		"bindings": { "doesNotExist": "testObjWithInt" },
+		"definitions": { "testObjWithInt": { "Int": "forty-two", "Missing": 1 } },
+		"globals": { "Missing": 1 }
+	}`))
+
+	s.Require().IsType(&Goij.ConfigurationError{}, err)
+	s.Assert().Equal(
+		[]string{
+			"bindings.doesNotExist: interface not found in type registry",
+			"definitions.testObjWithInt.Int: unable to convert: 'forty-two' to type: int",
+			"definitions.testObjWithInt.Missing: no exported field with this name exists on struct: 'test.testObjWithInt'",
+			"globals.Missing: no struct in the type registry has an exported field with this name",
+		},
+		err.(*Goij.ConfigurationError).Problems,
+	)
+}
+
+func (s *InjectorTestSuite) TestConfigurationGlobalIsConvertedToEachFieldWithTheName() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithEnvTags", Implementation: testObjWithEnvTags{}},
+			{Name: "github.com/j7mbo/goij/test.testObjWithPort", Implementation: testObjWithPort{}},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+
+	ij.Define("testObjWithEnvTags", "Host", "localhost")
+
+	s.Require().NoError(ij.LoadConfig(strings.NewReader(`{ "globals": { "Port": 8080 } }`)))
+
+	s.Assert().Equal(8080, ij.Make("testObjWithEnvTags").(*testObjWithEnvTags).Port)
+	s.Assert().Equal(testPort(8080), ij.Make("testObjWithPort").(*testObjWithPort).Port)
+}
+
+func (s *InjectorTestSuite) TestConfigurationGlobalThatConvertsToNoFieldListsTheirTypes() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithEnvTags", Implementation: testObjWithEnvTags{}},
+			{Name: "github.com/j7mbo/goij/test.testObjWithPort", Implementation: testObjWithPort{}},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+
+	err := ij.LoadConfig(strings.NewReader(`{ "globals": { "Port": "eighty" } }`))
+
+	s.Require().IsType(&Goij.ConfigurationError{}, err)
+	s.Assert().Equal(
+		[]string{`globals.Port: unable to convert: "eighty" to the type of any field with this name: int, test.testPort`},
+		err.(*Goij.ConfigurationError).Problems,
+	)
+}
+
+func (s *InjectorTestSuite) TestCanDefineFactoryArgumentByPosition() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementation: testObjWithInt{}},
+		},
+		RegistryFactories: []TypeRegistry.RegistryFactory{
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementations: []interface{}{FactoryWithIntArg}},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+	ij.DefineArg("test.FactoryWithIntArg", 0, 42)
+
+	s.Assert().Equal(42, ij.Make("testObjWithInt").(testObjWithInt).Int)
+}
+
+func (s *InjectorTestSuite) TestCanDefineDelegateArgumentByPositionWithTypeName() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementation: testObjWithInt{}},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+
+	ij.Delegate("testObjWithInt", FactoryWithPointerScalarArg)
+	ij.DefineArg("testObjWithInt", 0, 42)
+
+	s.Assert().Equal(42, ij.Make("testObjWithInt").(testObjWithInt).Int)
+}
+
+func (s *InjectorTestSuite) TestFactoryArgumentNamesFromRegistryCanBeDefined() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementation: testObjWithInt{}},
+		},
+		RegistryFactories: []TypeRegistry.RegistryFactory{
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementations: []interface{}{FactoryWithIntArg}},
+		},
+		RegistryFactoryArguments: []TypeRegistry.RegistryFactoryArguments{
+			{Name: "github.com/j7mbo/goij/test.FactoryWithIntArg", Arguments: []string{"globallyDefineMePlease"}},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+	ij.Define("test.FactoryWithIntArg", "globallyDefineMePlease", 42)
+
+	s.Assert().Equal(42, ij.Make("testObjWithInt").(testObjWithInt).Int)
+
+	ij = Goij.NewInjector(TypeRegistry.New(registry), nil)
+	ij.DefineGlobal("globallyDefineMePlease", 69)
+
+	s.Assert().Equal(69, ij.Make("testObjWithInt").(testObjWithInt).Int)
+}
+
+func (s *InjectorTestSuite) TestDefiningArgumentWithWrongTypePanics() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementation: testObjWithInt{}},
+		},
+		RegistryFactories: []TypeRegistry.RegistryFactory{
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementations: []interface{}{FactoryWithIntArg}},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+	ij.DefineArg("FactoryWithIntArg", 0, "forty-two")
+
+	s.Assert().Panics(func() {
+		ij.Make("testObjWithInt")
+	})
+}
+
+func (s *InjectorTestSuite) TestCanDefineTypeForAllFieldsOfThatType() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithTimeouts", Implementation: testObjWithTimeouts{}},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+	ij.DefineType(time.Duration(0), 5*time.Second)
+	ij.DefineNamedType(time.Duration(0), "WriteTimeout", 10*time.Second)
+
+	obj := ij.Make("testObjWithTimeouts").(*testObjWithTimeouts)
+
+	s.Assert().Equal(5*time.Second, obj.ReadTimeout)
+	s.Assert().Equal(10*time.Second, obj.WriteTimeout)
+	s.Assert().Equal(0, obj.Retries)
+}
+
+func (s *InjectorTestSuite) TestGlobalDefinitionOfUnassignableTypeIsIgnored() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithTimeouts", Implementation: testObjWithTimeouts{}},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+	ij.DefineGlobal("ReadTimeout", "five seconds")
+	ij.DefineGlobal("Retries", 3)
+	ij.DefineType(time.Duration(0), 5*time.Second)
+
+	obj := ij.Make("testObjWithTimeouts").(*testObjWithTimeouts)
+
+	s.Assert().Equal(5*time.Second, obj.ReadTimeout)
+	s.Assert().Equal(3, obj.Retries)
+}
+
+func (s *InjectorTestSuite) TestDefinitionThatCannotBeConvertedPanicsWithClearMessage() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithTimeouts", Implementation: testObjWithTimeouts{}},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+	ij.Define("testObjWithTimeouts", "ReadTimeout", "five seconds")
+
+	s.Assert().PanicsWithValue(
+		"Definition for field: 'ReadTimeout' on object: *test.testObjWithTimeouts could not be injected: unable to "+
+			"convert: 'five seconds' to type: time.Duration",
+		func() { ij.Make("testObjWithTimeouts") },
+	)
+}
+
+func (s *InjectorTestSuite) TestCanDefineInterfaceTypeForFields() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjToMake", Implementation: testObjToMake{}},
+			{Name: "github.com/j7mbo/goij/test.testObj", Implementation: testObj{}},
+			{Name: "github.com/j7mbo/goij/test.testObj2", Implementation: testObj2{}},
+		},
+		RegistryInterfaces: []TypeRegistry.RegistryInterface{
+			{Name: "github.com/j7mbo/goij/test.testInterface", Implementation: (*testInterface)(nil)},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+	ij.DefineType((*testInterface)(nil), &testObj2{})
+
+	s.Assert().IsType(&testObj2{}, ij.Make("testObjToMake").(*testObjToMake).Dep)
+}
+
+func (s *InjectorTestSuite) TestCanDefineTypeForFactoryArguments() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementation: testObjWithInt{}},
+		},
+		RegistryFactories: []TypeRegistry.RegistryFactory{
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementations: []interface{}{FactoryWithIntArg}},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+	ij.DefineType(0, 42)
+
+	s.Assert().Equal(42, ij.Make("testObjWithInt").(testObjWithInt).Int)
+}
+
+func (s *InjectorTestSuite) TestDefinitionsAreConvertedToFieldTypes() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithConvertibleFields", Implementation: testObjWithConvertibleFields{}},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+	ij.Define("testObjWithConvertibleFields", "Port", "8080")
+	ij.Define("testObjWithConvertibleFields", "Timeout", "5s")
+	ij.Define("testObjWithConvertibleFields", "Endpoint", "https://github.com/j7mbo/goij")
+	ij.Define("testObjWithConvertibleFields", "Weights", []interface{}{1, 2.5})
+	ij.Define("testObjWithConvertibleFields", "Size", int32(42))
+	ij.Define("testObjWithConvertibleFields", "IP", "127.0.0.1")
+
+	obj := ij.Make("testObjWithConvertibleFields").(*testObjWithConvertibleFields)
+
+	s.Assert().Equal(testPort(8080), obj.Port)
+	s.Assert().Equal(5*time.Second, obj.Timeout)
+	s.Assert().Equal("github.com", obj.Endpoint.Host)
+	s.Assert().Equal([]float64{1, 2.5}, obj.Weights)
+	s.Assert().Equal(int64(42), obj.Size)
+	s.Assert().Equal("127.0.0.1", obj.IP.String())
+}
+
+func (s *InjectorTestSuite) TestOverflowingDefinitionIsNotConverted() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithConvertibleFields", Implementation: testObjWithConvertibleFields{}},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+	ij.Define("testObjWithConvertibleFields", "Endpoint", "https://github.com/j7mbo/goij")
+	ij.Define("testObjWithConvertibleFields", "Small", 300)
+
+	s.Assert().Panics(func() {
+		ij.Make("testObjWithConvertibleFields")
+	})
+}
+
+func (s *InjectorTestSuite) TestNumbersAreNotConvertedToDurationsAsTheyHaveNoUnit() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithConvertibleFields", Implementation: testObjWithConvertibleFields{}},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+	ij.Define("testObjWithConvertibleFields", "Endpoint", "https://github.com/j7mbo/goij")
+	ij.Define("testObjWithConvertibleFields", "Timeout", 30)
+
+	defer func() {
+		s.Assert().Contains(fmt.Sprint(recover()), "value: 30 of type: int has no unit for type: time.Duration")
+	}()
+
+	ij.Make("testObjWithConvertibleFields")
+}
+
+func (s *InjectorTestSuite) TestCanRegisterConverterForCustomTypes() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithConvertibleFields", Implementation: testObjWithConvertibleFields{}},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+	ij.Define("testObjWithConvertibleFields", "Endpoint", "https://github.com/j7mbo/goij")
+	ij.Define("testObjWithConvertibleFields", "Port", "http")
+	ij.RegisterConverter(testPort(0), func(value interface{}) (interface{}, error) {
+		if value == "http" {
+			return testPort(80), nil
+		}
+
+		return nil, errors.New("unknown service")
+	})
+
+	s.Assert().Equal(testPort(80), ij.Make("testObjWithConvertibleFields").(*testObjWithConvertibleFields).Port)
+}
+
+func (s *InjectorTestSuite) TestVerifyWithResolvableGraphReturnsNoErrors() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObj", Implementation: testObj{}},
+			{Name: "github.com/j7mbo/goij/test.testObjToMake", Implementation: testObjToMake{}},
+		},
+		RegistryInterfaces: []TypeRegistry.RegistryInterface{
+			{Name: "github.com/j7mbo/goij/test.testInterface", Implementation: (*testInterface)(nil)},
+		},
+	}
+
+	s.Assert().Empty(Goij.NewInjector(TypeRegistry.New(registry), nil).Verify())
+}
+
+func (s *InjectorTestSuite) TestVerifyReportsEveryProblemWithItsPath() {
+	type Dep struct{}
+	type Obj struct {
+		First  testInterface
+		Second *Dep
+		Third  testObjWithEnvTags
+	}
+
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.Obj", Implementation: Obj{}},
+			{Name: "github.com/j7mbo/goij/test.testObj", Implementation: testObj{}},
+			{Name: "github.com/j7mbo/goij/test.testObj2", Implementation: testObj2{}},
+			{Name: "github.com/j7mbo/goij/test.testObjWithEnvTags", Implementation: testObjWithEnvTags{}},
+		},
+		RegistryInterfaces: []TypeRegistry.RegistryInterface{
+			{Name: "github.com/j7mbo/goij/test.testInterface", Implementation: (*testInterface)(nil)},
+		},
+	}
+
+	errs := Goij.NewInjector(TypeRegistry.New(registry), nil).Verify("github.com/j7mbo/goij/test.Obj")
+
+	s.Require().Len(errs, 3)
+	s.Assert().EqualError(
+		errs[0],
+		"Unable to resolve: 'Obj.First' for: 'github.com/j7mbo/goij/test.Obj': Multiple implementing types were "+
+			"found for interface: 'github.com/j7mbo/goij/test.testInterface', specify one with bind()",
+	)
+	s.Assert().EqualError(
+		errs[1],
+		"Unable to resolve: 'Obj.Second' for: 'github.com/j7mbo/goij/test.Obj': No type found in registry for "+
+			"name: 'Second', did you forget to register it?",
+	)
+	s.Assert().Equal("Obj.Third.Host", errs[2].(*Goij.ResolutionIssue).Path)
+}
+
+func (s *InjectorTestSuite) TestVerifyDoesNotCallDelegatesButChecksTheirArguments() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementation: testObjWithInt{}},
+		},
+		RegistryInterfaces: []TypeRegistry.RegistryInterface{
+			{Name: "github.com/j7mbo/goij/test.testInterface", Implementation: (*testInterface)(nil)},
+		},
+	}
+
+	called := false
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+	ij.Delegate("github.com/j7mbo/goij/test.testObjWithInt", func(dep testInterface) testObjWithInt {
+		called = true
+
+		return testObjWithInt{}
+	})
+
+	errs := ij.Verify("github.com/j7mbo/goij/test.testObjWithInt")
+
+	s.Assert().False(called)
+	s.Require().Len(errs, 1)
+	s.Assert().Equal("testObjWithInt.arg0", errs[0].(*Goij.ResolutionIssue).Path)
+}
+
+func (s *InjectorTestSuite) TestVerifyReportsCircularDependencies() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testCircularObj", Implementation: testCircularObj{}},
+			{Name: "github.com/j7mbo/goij/test.testCircularDep", Implementation: testCircularDep{}},
+		},
+	}
+
+	errs := Goij.NewInjector(TypeRegistry.New(registry), nil).Verify("github.com/j7mbo/goij/test.testCircularObj")
+
+	s.Require().Len(errs, 1)
+	s.Assert().EqualError(
+		errs[0],
+		"Unable to resolve: 'testCircularObj.Dep.Obj' for: 'github.com/j7mbo/goij/test.testCircularObj': "+
+			"Circular dependency on type: 'test.testCircularObj'",
+	)
+}
+
+func (s *InjectorTestSuite) TestMakeInDiagnosticsModePanicsWithEveryIssue() {
+	type Dep struct{}
+	type Obj struct {
+		First  testInterface
+		Second *Dep
+		Third  testObjWithInt
+	}
+
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.Obj", Implementation: Obj{}},
+			{Name: "github.com/j7mbo/goij/test.testObj", Implementation: testObj{}},
+			{Name: "github.com/j7mbo/goij/test.testObj2", Implementation: testObj2{}},
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementation: testObjWithInt{}},
+		},
+		RegistryInterfaces: []TypeRegistry.RegistryInterface{
+			{Name: "github.com/j7mbo/goij/test.testInterface", Implementation: (*testInterface)(nil)},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+	ij.Define("testObjWithInt", "Int", "not a number")
+	ij.Diagnostics(true)
+
+	err := recoverError(func() {
+		ij.Make("github.com/j7mbo/goij/test.Obj")
+	})
+
+	s.Require().IsType(&Goij.ResolutionError{}, err)
+
+	issues := err.(*Goij.ResolutionError).Issues
+
+	s.Require().Len(issues, 3)
+	s.Assert().Equal(Goij.IssueAmbiguousInterface, issues[0].Kind)
+	s.Assert().Equal("Obj.First", issues[0].Path)
+	s.Assert().Equal(Goij.IssueMissingType, issues[1].Kind)
+	s.Assert().Equal("Obj.Second", issues[1].Path)
+	s.Assert().Equal(Goij.IssueDefinitionMismatch, issues[2].Kind)
+	s.Assert().Equal("Obj.Third.Int", issues[2].Path)
+
+	/* The incomplete object must not have been cached. */
+	s.Assert().Panics(func() {
+		ij.Make("github.com/j7mbo/goij/test.Obj")
+	})
+}
+
+func (s *InjectorTestSuite) TestResolutionErrorGroupsIssuesByRoot() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjToMake", Implementation: testObjToMake{}},
+			{Name: "github.com/j7mbo/goij/test.testObjWithEnvTags", Implementation: testObjWithEnvTags{}},
+		},
+		RegistryInterfaces: []TypeRegistry.RegistryInterface{
+			{Name: "github.com/j7mbo/goij/test.testInterface", Implementation: (*testInterface)(nil)},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+
+	err := Goij.NewResolutionError(ij.Verify("testObjToMake", "testObjWithEnvTags"))
+
+	s.Assert().EqualError(
+		err,
+		"Unable to resolve 2 issue(s) for 2 type(s):\n"+
+			"'testObjToMake':\n"+
+			"  - testObjToMake.Dep (missing implementation): Could not initialise interface dependency unless there "+
+			"is exactly one implementing type in the registry or it has been bound to a single type with bind().\n"+
+			"'testObjWithEnvTags':\n"+
+			"  - testObjWithEnvTags.Host (missing environment variable): Environment variable: 'GOIJ_TEST_HOST' is "+
+			"required for field: 'Host' but is not set",
+	)
+	s.Assert().Nil(Goij.NewResolutionError(nil))
+}
+
+func (s *InjectorTestSuite) TestGraphContainsStructsInterfacesAndBindings() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObj", Implementation: testObj{}},
+			{Name: "github.com/j7mbo/goij/test.testObjToMake", Implementation: testObjToMake{}},
+		},
+		RegistryInterfaces: []TypeRegistry.RegistryInterface{
+			{Name: "github.com/j7mbo/goij/test.testInterface", Implementation: (*testInterface)(nil)},
+		},
+	}
+
+	graph := Goij.NewInjector(TypeRegistry.New(registry), nil).Graph("github.com/j7mbo/goij/test.testObjToMake")
+
+	s.Assert().Equal(
+		[]*Goij.GraphNode{
+			{ID: "n0", Kind: Goij.NodeStruct, Label: "test.testObjToMake"},
+			{ID: "n1", Kind: Goij.NodeStruct, Label: "test.testObj"},
+			{ID: "n2", Kind: Goij.NodeInterface, Label: "test.testInterface"},
+		},
+		graph.Nodes,
+	)
+	s.Assert().Equal(
+		"digraph Goij {\n"+
+			"\tn0 [label=\"struct\\ntest.testObjToMake\" shape=box];\n"+
+			"\tn1 [label=\"struct\\ntest.testObj\" shape=box];\n"+
+			"\tn2 [label=\"interface\\ntest.testInterface\" shape=ellipse];\n"+
+			"\tn2 -> n1 [label=\"single implementation\" style=dashed];\n"+
+			"\tn0 -> n2 [label=\"Dep\" style=solid];\n"+
+			"}\n",
+		graph.DOT(),
+	)
+	s.Assert().Contains(graph.Mermaid(), "n2 -.->|\"single implementation\"| n1")
+}
+
+func (s *InjectorTestSuite) TestGraphContainsFactoriesSharedObjectsAndDefinitions() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementation: testObjWithInt{}},
+			{Name: "github.com/j7mbo/goij/test.testDepForFactoryWithArgs", Implementation: testDepForFactoryWithArgs{}},
+			{Name: "github.com/j7mbo/goij/test.testObjWithTimeouts", Implementation: testObjWithTimeouts{}},
+		},
+		RegistryFactories: []TypeRegistry.RegistryFactory{
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementations: []interface{}{FactoryWithArgs}},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+	ij.Share(testDepForFactoryWithArgs{Int: 42})
+	ij.Define("testObjWithTimeouts", "ReadTimeout", "5s")
+
+	graph := ij.Graph("testObjWithInt", "testObjWithTimeouts")
+
+	s.Assert().Equal(
+		[]*Goij.GraphNode{
+			{ID: "n0", Kind: Goij.NodeFactory, Label: "test.FactoryWithArgs"},
+			{ID: "n1", Kind: Goij.NodeShared, Label: "test.testDepForFactoryWithArgs"},
+			{ID: "n2", Kind: Goij.NodeStruct, Label: "test.testObjWithTimeouts"},
+			{ID: "n3", Kind: Goij.NodeDefinition, Label: "definition (string)"},
+		},
+		graph.Nodes,
+	)
+	s.Assert().Equal(
+		[]*Goij.GraphEdge{
+			{From: "n0", To: "n1", Kind: Goij.EdgeArgument, Label: "arg0"},
+			{From: "n2", To: "n3", Kind: Goij.EdgeField, Label: "ReadTimeout"},
+		},
+		graph.Edges,
+	)
+
+	document, err := graph.JSON()
+
+	s.Require().NoError(err)
+	s.Assert().Contains(string(document), `"kind": "factory"`)
+}
+
+func (s *InjectorTestSuite) TestExplainDescribesBindingsAndTheImplementationsNotUsed() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObj", Implementation: testObj{}},
+			{Name: "github.com/j7mbo/goij/test.testObj2", Implementation: testObj2{}},
+			{Name: "github.com/j7mbo/goij/test.testObjToMake", Implementation: testObjToMake{}},
+		},
+		RegistryInterfaces: []TypeRegistry.RegistryInterface{
+			{Name: "github.com/j7mbo/goij/test.testInterface", Implementation: (*testInterface)(nil)},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+	ij.Bind("testInterface", "github.com/j7mbo/goij/test.testObj2")
+
+	resolution, err := ij.Explain("github.com/j7mbo/goij/test.testObjToMake")
+
+	s.Require().NoError(err)
+	s.Assert().Equal(Goij.SourceRegistry, resolution.Source)
+	s.Require().Len(resolution.Dependencies, 1)
+
+	dep := resolution.Dependencies[0]
+
+	s.Assert().Equal(Goij.SourceRegistry, dep.Source)
+	s.Assert().Equal(Goij.BindingShortName, dep.Binding)
+	s.Assert().Equal(reflect.TypeOf(testObj2{}), dep.Provided)
+	s.Assert().Equal([]string{"implementation: test.testObj, as the interface is bound with short-name bind"}, dep.Alternatives)
+	s.Assert().Equal(
+		"github.com/j7mbo/goij/test.testObjToMake: registry test.testObjToMake\n"+
+			"  Dep: registry test.testObj2 (short-name bind of test.testInterface)\n"+
+			"    - not used: implementation: test.testObj, as the interface is bound with short-name bind",
+		resolution.String(),
+	)
+}
+
+func (s *InjectorTestSuite) TestExplainDescribesDefinitionsAndTheDefinitionsNotUsed() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithTimeouts", Implementation: testObjWithTimeouts{}},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+	ij.Define("testObjWithTimeouts", "ReadTimeout", "5s")
+	ij.DefineType(time.Duration(0), time.Second)
+	ij.DefineGlobal("Retries", "three")
+
+	resolution, err := ij.Explain("testObjWithTimeouts")
+
+	s.Require().NoError(err)
+	s.Require().Len(resolution.Dependencies, 3)
+	s.Assert().Equal(Goij.SourceDefinition, resolution.Dependencies[0].Source)
+	s.Assert().Equal(
+		[]string{"type definition of type: time.Duration, as it is less specific"},
+		resolution.Dependencies[0].Alternatives,
+	)
+	s.Assert().Equal(Goij.SourceTypeDefinition, resolution.Dependencies[1].Source)
+	s.Assert().Equal(Goij.SourceZeroValue, resolution.Dependencies[2].Source)
+	s.Assert().Equal(
+		[]string{"global definition of type: string, as it can't be converted to: int"},
+		resolution.Dependencies[2].Alternatives,
+	)
+}
+
+func (s *InjectorTestSuite) TestExplainDescribesFactoriesAndSharedObjects() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementation: testObjWithInt{}},
+			{Name: "github.com/j7mbo/goij/test.testDepForFactoryWithArgs", Implementation: testDepForFactoryWithArgs{}},
+		},
+		RegistryFactories: []TypeRegistry.RegistryFactory{
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementations: []interface{}{FactoryWithArgs}},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+	ij.Share(testDepForFactoryWithArgs{Int: 42})
+
+	resolution, err := ij.Explain("testObjWithInt")
+
+	s.Require().NoError(err)
+	s.Assert().Equal(
+		"testObjWithInt: factory github.com/j7mbo/goij/test.FactoryWithArgs\n"+
+			"  - not used: registry struct: test.testObjWithInt, as the factory: "+
+			"github.com/j7mbo/goij/test.FactoryWithArgs is used first\n"+
+			"  arg0: shared test.testDepForFactoryWithArgs",
+		resolution.String(),
+	)
+}
+
+func (s *InjectorTestSuite) TestPlanListsStepsInConstructionOrder() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.ParentObjForObjWithSharedDep", Implementation: ParentObjForObjWithSharedDep{}},
+			{Name: "github.com/j7mbo/goij/test.ObjWithSharedDep", Implementation: ObjWithSharedDep{}},
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementation: testObjWithInt{}},
+		},
+		RegistryFactories: []TypeRegistry.RegistryFactory{
+			{Name: "github.com/j7mbo/goij/test.ObjWithSharedDep", Implementations: []interface{}{NewObjWithSharedDep}},
+		},
+	}
+
+	plan, err := Goij.NewInjector(TypeRegistry.New(registry), nil).Plan("ParentObjForObjWithSharedDep")
+
+	s.Require().NoError(err)
+	s.Assert().Equal(
+		"initialise test.ParentObjForObjWithSharedDep for ParentObjForObjWithSharedDep\n"+
+			"initialise test.testObjWithInt for ParentObjForObjWithSharedDep.ObjWithSharedDep.arg0\n"+
+			"call factory github.com/j7mbo/goij/test.NewObjWithSharedDep(arg0: registry test.testObjWithInt) "+
+			"for ParentObjForObjWithSharedDep.ObjWithSharedDep\n"+
+			"set ParentObjForObjWithSharedDep.ObjWithSharedDep from factory github.com/j7mbo/goij/test.NewObjWithSharedDep\n"+
+			"cache test.ParentObjForObjWithSharedDep",
+		plan.String(),
+	)
+}
+
+func (s *InjectorTestSuite) TestPlansFromDifferentConfigurationsCanBeCompared() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementation: testObjWithInt{}},
+			{Name: "github.com/j7mbo/goij/test.testDepForFactoryWithArgs", Implementation: testDepForFactoryWithArgs{}},
+		},
+	}
+
+	called := false
+
+	before := Goij.NewInjector(TypeRegistry.New(registry), nil)
+	after := Goij.NewInjector(TypeRegistry.New(registry), nil)
+	after.Share(testDepForFactoryWithArgs{Int: 42})
+	after.Delegate("testObjWithInt", func(dep testDepForFactoryWithArgs) testObjWithInt {
+		called = true
+
+		return testObjWithInt{Int: dep.Int}
+	})
+
+	beforePlan, _ := before.Plan("testObjWithInt")
+	afterPlan, _ := after.Plan("testObjWithInt")
+
+	differences := beforePlan.Compare(afterPlan)
+
+	s.Assert().False(called)
+	s.Require().Len(differences, 3)
+	s.Assert().Equal("- initialise test.testObjWithInt for testObjWithInt", differences[0])
+	s.Assert().Equal("- cache test.testObjWithInt", differences[1])
+	s.Assert().Regexp(`^\+ call delegate .*\(arg0: shared test.testDepForFactoryWithArgs\) for testObjWithInt$`, differences[2])
+	s.Assert().Nil(beforePlan.Compare(beforePlan))
+}
+
+func (s *InjectorTestSuite) TestLoggerReceivesStructuredFieldsForEnabledLevelsOnly() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementation: testObjWithInt{}},
+		},
+	}
+
+	logger := &recordingLogger{level: Logger.LevelDebug}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), logger)
+	ij.Define("testObjWithInt", "Int", 42)
+	ij.Make("testObjWithInt")
+
+	definition := logger.find("Injecting definition")
+	finished := logger.find("Make finished")
+
+	s.Require().NotNil(definition)
+	s.Require().NotNil(finished)
+	s.Assert().Equal(Logger.LevelDebug, definition.level)
+	s.Assert().Equal("[DEBUG] - Injecting definition field=Int type=int source=definition", definition.text)
+	s.Assert().Equal(Logger.LevelInfo, finished.level)
+	s.Assert().Contains(finished.text, "duration=")
+	s.Assert().Nil(logger.find("Found field"))
+}
+
+func (s *InjectorTestSuite) TestFuncLoggerOnlyLogsFromItsLevel() {
+	var debugged, errored []string
+
+	logger := Logger.New(
+		func(msg ...interface{}) { debugged = append(debugged, fmt.Sprint(msg...)) },
+		func(msg ...interface{}) { errored = append(errored, fmt.Sprint(msg...)) },
+	)
+	logger.SetLevel(Logger.LevelInfo)
+
+	logger.Log(Logger.LevelDebug, "Ignored")
+	logger.Log(Logger.LevelInfo, "Make requested", Logger.Any("name", "a b"))
+	logger.Log(Logger.LevelWarn, "Ignoring global definition", Logger.Type(0))
+
+	s.Assert().False(logger.Enabled(Logger.LevelTrace))
+	s.Assert().Equal([]string{`[INFO] - Make requested name="a b"`}, debugged)
+	s.Assert().Equal([]string{"[WARN] - Ignoring global definition type=int"}, errored)
+}
+
+func (s *InjectorTestSuite) TestLoggerAdaptersLogStructuredFieldsAtTheirLevel() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementation: testObjWithInt{}},
+		},
+	}
+
+	var slogOutput, logrusOutput, jsonOutput bytes.Buffer
+
+	logrusLogger := logrus.New()
+	logrusLogger.SetOutput(&logrusOutput)
+	logrusLogger.SetFormatter(&logrus.JSONFormatter{})
+	logrusLogger.SetLevel(logrus.DebugLevel)
+
+	loggers := map[*bytes.Buffer]Logger.Logger{
+		&slogOutput: Logger.NewSlogLogger(
+			slog.New(slog.NewJSONHandler(&slogOutput, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		),
+		&logrusOutput: Logger.NewLogrusLogger(logrusLogger),
+		&jsonOutput:   Logger.NewJSONLogger(&jsonOutput, Logger.LevelDebug),
+	}
+
+	for output, logger := range loggers {
+		ij := Goij.NewInjector(TypeRegistry.New(registry), logger)
+		ij.Define("testObjWithInt", "Int", 42)
+		ij.Make("testObjWithInt")
+
+		s.Assert().NotContains(output.String(), "Found field")
+
+		var definition map[string]interface{}
+
+		for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+			var entry map[string]interface{}
+
+			s.Require().NoError(json.Unmarshal([]byte(line), &entry))
+
+			if entry["msg"] == "Injecting definition" {
+				definition = entry
+			}
+		}
+
+		s.Require().NotNil(definition, output.String())
+		s.Assert().Equal("Int", definition["field"])
+		s.Assert().Equal("int", definition["type"])
+		s.Assert().Equal("definition", definition["source"])
+		s.Assert().Contains([]interface{}{"DEBUG", "debug"}, definition["level"])
+	}
+}
+
+func (s *InjectorTestSuite) TestEventsAreEmittedWithTheirParentAndDuration() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.ParentObjForObjWithSharedDep", Implementation: ParentObjForObjWithSharedDep{}},
+			{Name: "github.com/j7mbo/goij/test.ObjWithSharedDep", Implementation: ObjWithSharedDep{}},
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementation: testObjWithInt{}},
+		},
+		RegistryFactories: []TypeRegistry.RegistryFactory{
+			{Name: "github.com/j7mbo/goij/test.ObjWithSharedDep", Implementations: []interface{}{NewObjWithSharedDep}},
+		},
+	}
+
+	var events []Goij.Event
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+	ij.OnEvent(func(event Goij.Event) { events = append(events, event) })
+	ij.Define("testObjWithInt", "Int", 42)
+	ij.Make("ParentObjForObjWithSharedDep")
+
+	findEvent := func(kind Goij.EventKind) *Goij.Event {
+		for i := range events {
+			if events[i].Kind == kind {
+				return &events[i]
+			}
+		}
+
+		return nil
+	}
+
+	start, end := events[0], events[len(events)-1]
+	factory := findEvent(Goij.EventFactoryCalled)
+	definition := findEvent(Goij.EventDefinitionApplied)
+
+	s.Require().NotNil(factory)
+	s.Require().NotNil(definition)
+	s.Assert().Equal(Goij.EventResolveStart, start.Kind)
+	s.Assert().Equal(uint64(0), start.ParentID)
+	s.Assert().Equal(Goij.EventResolveEnd, end.Kind)
+	s.Assert().Equal(start.ID, end.ID)
+	s.Assert().Equal(reflect.TypeOf(&ParentObjForObjWithSharedDep{}), end.Type)
+	s.Assert().True(end.Duration >= factory.Duration)
+	s.Assert().Equal(start.ID, factory.ParentID)
+	s.Assert().Equal("github.com/j7mbo/goij/test.NewObjWithSharedDep", factory.Function)
+	s.Assert().Equal(factory.ID, definition.ParentID)
+	s.Assert().Equal("Int", definition.Field)
+	s.Assert().Equal(Goij.SourceDefinition, definition.Source)
+	s.Assert().Equal(Goij.EventCacheMiss, events[1].Kind)
+
+	events = nil
+
+	ij.Make("ParentObjForObjWithSharedDep")
+
+	s.Assert().Equal(Goij.EventCacheHit, events[1].Kind)
+	s.Assert().Equal(reflect.TypeOf(ParentObjForObjWithSharedDep{}), events[1].Type)
+}
+
+func (s *InjectorTestSuite) TestEventsAreEmittedForBindingsAndErrors() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObj", Implementation: testObj{}},
+			{Name: "github.com/j7mbo/goij/test.testObj2", Implementation: testObj2{}},
+			{Name: "github.com/j7mbo/goij/test.testObjToMake", Implementation: testObjToMake{}},
+		},
+		RegistryInterfaces: []TypeRegistry.RegistryInterface{
+			{Name: "github.com/j7mbo/goij/test.testInterface", Implementation: (*testInterface)(nil)},
+		},
+	}
+
+	kinds := map[Goij.EventKind][]Goij.Event{}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+	ij.OnEvent(func(event Goij.Event) { kinds[event.Kind] = append(kinds[event.Kind], event) })
+	ij.Bind("github.com/j7mbo/goij/test.testInterface", "github.com/j7mbo/goij/test.testObj2")
+	ij.Make("testObjToMake")
+
+	s.Require().Len(kinds[Goij.EventBindingApplied], 1)
+	s.Assert().Equal("Dep", kinds[Goij.EventBindingApplied][0].Field)
+	s.Assert().Equal(Goij.BindingFullName, kinds[Goij.EventBindingApplied][0].Binding)
+	s.Assert().Equal(reflect.TypeOf(testObj2{}), kinds[Goij.EventBindingApplied][0].Type)
+
+	s.Assert().Panics(func() {
+		ij.Make("doesnt.exist")
+	})
+
+	s.Require().Len(kinds[Goij.EventError], 1)
+	s.Assert().Contains(kinds[Goij.EventError][0].Err.Error(), "doesnt.exist")
+
+	ends := kinds[Goij.EventResolveEnd]
+
+	s.Assert().EqualError(ends[len(ends)-1].Err, kinds[Goij.EventError][0].Err.Error())
+}
+
+func (s *InjectorTestSuite) TestConfigurationChangesAreUsedAfterTypesHaveBeenResolved() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementation: testObjWithInt{}},
+			{Name: "github.com/j7mbo/goij/test.testObj", Implementation: testObj{}},
+			{Name: "github.com/j7mbo/goij/test.testObjToMake", Implementation: testObjToMake{}},
+		},
+		RegistryInterfaces: []TypeRegistry.RegistryInterface{
+			{Name: "github.com/j7mbo/goij/test.testInterface", Implementation: (*testInterface)(nil)},
+		},
+	}
+
+	tr := TypeRegistry.New(registry)
+	ij := Goij.NewInjector(tr, nil)
+
+	makeInt := func() int {
+		results, err := ij.Call(func(obj *testObjWithInt) int { return obj.Int })
+
+		s.Require().NoError(err)
+
+		return results[0].(int)
+	}
+
+	s.Assert().Equal(0, makeInt())
+
+	ij.Define("testObjWithInt", "Int", 1)
+
+	s.Assert().Equal(1, makeInt())
+
+	ij.DefineGlobal("Int", 2)
+	ij.Define("testObjWithInt", "Int", 3)
+
+	s.Assert().Equal(3, makeInt())
+
+	resolution, err := ij.Explain("testObjToMake")
+
+	s.Require().NoError(err)
+	s.Assert().Equal(reflect.TypeOf(testObj{}), resolution.Dependencies[0].Provided)
+
+	tr.Add(TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObj2", Implementation: testObj2{}},
+		},
+	})
+
+	_, err = ij.Explain("testObjToMake")
+
+	s.Assert().Error(err)
+
+	ij.Bind("testInterface", "github.com/j7mbo/goij/test.testObj2")
+
+	resolution, err = ij.Explain("testObjToMake")
+
+	s.Require().NoError(err)
+	s.Assert().Equal(reflect.TypeOf(testObj2{}), resolution.Dependencies[0].Provided)
+}
+
+func (s *InjectorTestSuite) TestGeneratedConstructorsMakeTheSameGraphAsMake() {
+	ij := newFixturesInjector(s)
+
+	made := ij.Make("github.com/j7mbo/goij/test/fixtures.IndexController")
+
+	constructed, cleanup, err := constructors.ConstructIndexController(&fixtures.Cache{Size: 64})
+
+	s.Require().NoError(err)
+	s.Assert().Equal(made, constructed)
+	s.Assert().Equal("users", made.(*fixtures.IndexController).Users.Find(0))
+
+	cleanup()
+
+	s.Assert().True(constructed.Users.(*fixtures.MySQLUsers).DB.Closed)
+
+	users, _, err := constructors.ConstructUsers()
+
+	s.Require().NoError(err)
+	s.Assert().Equal(ij.Make("github.com/j7mbo/goij/test/fixtures.Users"), users)
+}
+
+func (s *InjectorTestSuite) TestGeneratedConstructorsAreUpToDate() {
+	var generated bytes.Buffer
+
+	err := Goij.GenerateConstructors(newFixturesInjector(s), &generated, Goij.ConstructorOptions{
+		Package:    "constructors",
+		ImportPath: "github.com/j7mbo/goij/test/fixtures/constructors",
+		Roots: []string{
+			"github.com/j7mbo/goij/test/fixtures.IndexController", "github.com/j7mbo/goij/test/fixtures.Users",
+		},
+	})
+
+	s.Require().NoError(err)
+
+	existing, err := os.ReadFile("fixtures/constructors/Constructors.go")
+
+	s.Require().NoError(err)
+	s.Assert().Equal(string(existing), generated.String())
+}
+
+func (s *InjectorTestSuite) TestConstructorsAreGeneratedByRunningAProgramWithTheRegistry() {
+	/* Written next to the fixture constructors, so that they are in the same package, but not as a .go file. */
+	file := "fixtures/constructors/Constructors.go.generated"
+
+	defer func() { _ = os.Remove(file) }()
+
+	/* A program left behind by an interrupted run is removed, and isn't mistaken for part of the registry. */
+	leftover := "fixtures/registry/_goij_constructors_interrupted"
+
+	s.Require().NoError(os.MkdirAll(leftover, 0755))
+	s.Require().NoError(os.WriteFile(filepath.Join(leftover, "main.go"), []byte("package main\n\ntype Leftover struct{}\n"), 0644))
+
+	defer func() { _ = os.RemoveAll(leftover) }()
+
+	var registry bytes.Buffer
+
+	registryGen := TypeRegistry.NewAutoRegistryGenerator(TypeRegistry.AutoGeneratedRegistryWriter{})
+
+	s.Require().NoError(registryGen.GenerateTo(&registry, TypeRegistry.Options{Dir: "fixtures", PackageName: "registry"}))
+	s.Assert().NotContains(registry.String(), "Leftover")
+
+	gen := TypeRegistry.NewConstructorGenerator()
+
+	err := gen.Generate(
+		file,
+		"fixtures/registry",
+		"fixtures/config.json",
+		"github.com/j7mbo/goij/test/fixtures.IndexController",
+		"github.com/j7mbo/goij/test/fixtures.Users",
+	)
+
+	s.Require().NoError(err)
+
+	generated, err := os.ReadFile(file)
+
+	s.Require().NoError(err)
+
+	existing, err := os.ReadFile("fixtures/constructors/Constructors.go")
+
+	s.Require().NoError(err)
+	s.Assert().Equal(string(existing), string(generated))
+
+	programs, err := filepath.Glob("fixtures/registry/_goij_constructors*")
+
+	s.Require().NoError(err)
+	s.Assert().Empty(programs)
+
+	err = gen.Generate(file, "fixtures/registry", "fixtures/missing.json", "github.com/j7mbo/goij/test/fixtures.Users")
+
+	s.Require().Error(err)
+	s.Assert().Contains(err.Error(), "Unable to generate constructors")
+	s.Assert().Contains(err.Error(), "missing.json")
+}
+
+func (s *InjectorTestSuite) TestConstructorsAreNotGeneratedForValuesOnlyKnownWhenTheProgramRuns() {
+	ij := newFixturesInjector(s)
+
+	ij.Delegate("github.com/j7mbo/goij/test/fixtures.Pool", func() *fixtures.Pool { return &fixtures.Pool{} })
+
+	err := Goij.GenerateConstructors(ij, io.Discard, Goij.ConstructorOptions{
+		Roots: []string{"github.com/j7mbo/goij/test/fixtures.Users"},
+	})
+
+	s.Require().Error(err)
+	s.Assert().Contains(err.Error(), "Users.DB: the function:")
+	s.Assert().Contains(err.Error(), "can't be called from generated code, as it is a closure")
+
+	err = Goij.GenerateConstructors(
+		Goij.NewInjector(TypeRegistry.New(fixturesregistry.GetRegistry()), nil),
+		io.Discard,
+		Goij.ConstructorOptions{Roots: []string{"github.com/j7mbo/goij/test/fixtures.Users"}},
+	)
+
+	s.Require().Error(err)
+	s.Assert().Contains(err.Error(), "Unable to generate a constructor for: 'github.com/j7mbo/goij/test/fixtures.Users'")
+	s.Assert().Contains(err.Error(), "Multiple implementing types were found for interface")
+}
+
+func (s *InjectorTestSuite) TestErrorsReturnedByFactoriesStopResolutionWithThePath() {
+	ij := newFixturesInjector(s)
+
+	ij.Define("github.com/j7mbo/goij/test/fixtures.Connection", "DSN", "")
+
+	err := recoverError(func() { ij.Make("github.com/j7mbo/goij/test/fixtures.IndexController") })
+
+	var issue *Goij.ResolutionIssue
+
+	s.Require().True(errors.As(err, &issue))
+	s.Assert().Equal(Goij.IssueFactoryError, issue.Kind)
+	s.Assert().Equal("github.com/j7mbo/goij/test/fixtures.IndexController", issue.Root)
+	s.Assert().Equal("IndexController.Users.DB", issue.Path)
+	s.Assert().Contains(issue.Reason, "NewPool returned an error: no DSN to connect to")
+	s.Assert().EqualError(errors.Unwrap(issue), "no DSN to connect to")
+
+	_, err = ij.Call(func(users fixtures.Users) {})
+
+	s.Require().True(errors.As(err, &issue))
+	s.Assert().Equal("Users.DB", issue.Path)
+
+	ij.Diagnostics(true)
+
+	err = recoverError(func() { ij.Make("github.com/j7mbo/goij/test/fixtures.IndexController") })
+
+	var resolutionError *Goij.ResolutionError
+
+	s.Require().True(errors.As(err, &resolutionError))
+	s.Require().Len(resolutionError.Issues, 1)
+	s.Assert().Equal(Goij.IssueFactoryError, resolutionError.Issues[0].Kind)
+	s.Assert().Equal("IndexController.Users.DB", resolutionError.Issues[0].Path)
+}
+
+func (s *InjectorTestSuite) TestErrorsReturnedByDelegatesCanBeCheckedWithErrorsIs() {
+	errUnavailable := errors.New("unavailable")
+
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementation: testObjWithInt{}},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+	ij.Delegate("github.com/j7mbo/goij/test.testObjWithInt", func() (*testObjWithInt, error) {
+		return &testObjWithInt{}, errUnavailable
+	})
+
+	err := recoverError(func() { ij.Make("github.com/j7mbo/goij/test.testObjWithInt") })
+
+	s.Assert().True(errors.Is(err, errUnavailable))
+	s.Assert().Contains(err.Error(), "The delegate:")
+}
+
+func (s *InjectorTestSuite) TestCleanupFunctionsAreCalledOnShutdownInReverseOrder() {
+	var calls []string
+
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithInt", Implementation: testObjWithInt{}},
+			{Name: "github.com/j7mbo/goij/test.testObjWithPointerInt", Implementation: testObjWithPointerInt{}},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+	ij.Delegate("github.com/j7mbo/goij/test.testObjWithInt", func() (*testObjWithInt, func(), error) {
+		return &testObjWithInt{}, func() { calls = append(calls, "int") }, nil
+	})
+	ij.Delegate("github.com/j7mbo/goij/test.testObjWithPointerInt", func() (*testObjWithPointerInt, func()) {
+		return &testObjWithPointerInt{}, func() { calls = append(calls, "pointer") }
+	})
+
+	ij.Make("github.com/j7mbo/goij/test.testObjWithInt")
+	ij.Make("github.com/j7mbo/goij/test.testObjWithPointerInt")
+
+	s.Assert().Empty(calls)
+
+	ij.Shutdown()
+	ij.Shutdown()
+
+	s.Assert().Equal([]string{"pointer", "int"}, calls)
+}
+
+func (s *InjectorTestSuite) TestInterfaceDelegateIsCalledAndCleanedUpOnceForAField() {
+	calls, cleanups := 0, 0
+
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjToMake", Implementation: testObjToMake{}},
+		},
+		RegistryInterfaces: []TypeRegistry.RegistryInterface{
+			{Name: "github.com/j7mbo/goij/test.testInterface", Implementation: (*testInterface)(nil)},
+		},
+	}
+
+	ij := Goij.NewInjector(TypeRegistry.New(registry), nil)
+	ij.Delegate("github.com/j7mbo/goij/test.testInterface", func() (testInterface, func(), error) {
+		calls++
+
+		return &testObj{}, func() { cleanups++ }, nil
+	})
+
+	s.IsType(&testObj{}, ij.Make("testObjToMake").(*testObjToMake).Dep)
+
+	ij.Shutdown()
+
+	s.Assert().Equal(1, calls)
+	s.Assert().Equal(1, cleanups)
+}
+
+func (s *InjectorTestSuite) TestRegistryImportPathsAreReadFromTheModulesInTheWorkspace() {
+	s.T().Setenv("GOWORK", "")
+
+	dir := s.T().TempDir()
+
+	files := map[string]string{
+		"go.work":                "go 1.21\n\nuse (\n\t./app // The application\n)\n",
+		"app/go.mod":             "module \"example.com/app\"\n\ngo 1.21\n",
+		"app/service/Server.go":  "package service\n\ntype Server struct{}\n",
+		"app/registry/doc.go":    "package registry\n",
+		"unused/go.mod":          "module example.com/unused\n",
+		"unused/Ignored.go":      "package unused\n\ntype Ignored struct{}\n",
+		"app/service/Server2.go": "package service\n\ntype Client struct{}\n",
+	}
+
+	for path, contents := range files {
+		s.Require().NoError(os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0755))
+		s.Require().NoError(os.WriteFile(filepath.Join(dir, path), []byte(contents), 0644))
+	}
+
+	gen := TypeRegistry.NewAutoRegistryGenerator(TypeRegistry.AutoGeneratedRegistryWriter{})
+	gen.Generate(filepath.Join(dir, "app/registry/Registry.go"), dir)
+
+	registry, err := os.ReadFile(filepath.Join(dir, "app/registry/Registry.go"))
+
+	s.Require().NoError(err)
+	s.Assert().True(strings.HasPrefix(string(registry), "package registry\n"))
+	s.Assert().Contains(string(registry), `Name: "example.com/app/service.Server"`)
+	s.Assert().Contains(string(registry), `Name: "example.com/app/service.Client"`)
+	s.Assert().NotContains(string(registry), "Ignored")
+}
+
+func (s *InjectorTestSuite) TestRegistryGenerationWarningsAreGivenToTheOption() {
+	s.T().Setenv("GOWORK", "")
+
+	dir := s.T().TempDir()
+
+	files := map[string]string{
+		"go.work":               "go 1.21\n\nuse ./app\n",
+		"app/go.mod":            "module example.com/app\n\ngo 1.21\n",
+		"app/service/Server.go": "package service\n\ntype Server struct{}\n",
+		"unused/a/go.mod":       "module example.com/a\n",
+		"unused/a/A.go":         "package a\n\ntype A struct{}\n",
+		"unused/b/go.mod":       "module example.com/b\n",
+		"unused/b/B.go":         "package b\n\ntype B struct{}\n",
+	}
+
+	for path, contents := range files {
+		s.Require().NoError(os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0755))
+		s.Require().NoError(os.WriteFile(filepath.Join(dir, path), []byte(contents), 0644))
+	}
+
+	gen := TypeRegistry.NewAutoRegistryGenerator(TypeRegistry.AutoGeneratedRegistryWriter{})
+
+	for _, cacheFile := range []string{"", filepath.Join(s.T().TempDir(), "cache.json")} {
+		/* The second time with a cache, everything is taken from it, and the warnings are given again. */
+		for i := 0; i < 2; i++ {
+			var warnings []string
+
+			err := gen.GenerateTo(io.Discard, TypeRegistry.Options{
+				Dir:       dir,
+				CacheFile: cacheFile,
+				Warning:   func(message string) { warnings = append(warnings, message) },
+			})
+
+			s.Require().NoError(err)
+			s.Require().Len(warnings, 2)
+			s.Assert().Contains(warnings[0], filepath.Join(dir, "unused/a"))
+			s.Assert().Contains(warnings[1], filepath.Join(dir, "unused/b"))
+		}
+	}
+}
+
+func (s *InjectorTestSuite) TestGeneratedRegistryIsTheSameEveryTime() {
+	dir := s.T().TempDir()
+
+	files := map[string]string{
+		"go.mod":                       "module example.com/app\n",
+		"a/service/Server.go":          "package service\n\ntype Server struct{}\n\nfunc NewServer(port int) *Server { return nil }\n",
+		"a/service/Client.go":          "package service\n\ntype Client struct{}\ntype Handler interface{ Handle() }\n",
+		"b/service/v2/Server.go":       "package service\n\ntype Server struct{}\n\nfunc NewServer() *Server { return nil }\n",
+		"c/TypeRegistry/Registry.go":   "package TypeRegistry\n\ntype Registry struct{}\n",
+		"c/TypeRegistry/Registries.go": "package TypeRegistry\n\ntype Registries struct{}\n",
+	}
+
+	for path, contents := range files {
+		s.Require().NoError(os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0755))
+		s.Require().NoError(os.WriteFile(filepath.Join(dir, path), []byte(contents), 0644))
+	}
+
+	var registries []string
+
+	for i := 0; i < 3; i++ {
+		gen := TypeRegistry.NewAutoRegistryGenerator(TypeRegistry.AutoGeneratedRegistryWriter{})
+		gen.Generate(filepath.Join(dir, "Registry.go"), dir)
+
+		registry, err := os.ReadFile(filepath.Join(dir, "Registry.go"))
+
+		s.Require().NoError(err)
+
+		registries = append(registries, string(registry))
+	}
+
+	s.Assert().Equal(registries[0], registries[1])
+	s.Assert().Equal(registries[0], registries[2])
+
+	source, err := format.Source([]byte(registries[0]))
+
+	s.Require().NoError(err)
+	s.Assert().Equal(registries[0], string(source))
+
+	s.Assert().Contains(registries[0], `service "example.com/app/a/service"`)
+	s.Assert().Contains(registries[0], `service2 "example.com/app/b/service/v2"`)
+	s.Assert().Contains(registries[0], `TypeRegistry2 "example.com/app/c/TypeRegistry"`)
+	s.Assert().Contains(registries[0], `Implementations: []interface{}{service2.NewServer}`)
+
+	/* Types are sorted by name, whichever file they are declared in. */
+	s.Assert().True(strings.Index(registries[0], "a/service.Client") < strings.Index(registries[0], "a/service.Server"))
+	s.Assert().True(
+		strings.Index(registries[0], "TypeRegistry2.Registries{}") < strings.Index(registries[0], "TypeRegistry2.Registry{}"),
+	)
+}
+
+func (s *InjectorTestSuite) TestGeneratedRegistryAliasesCompileWhateverThePackagesAreCalled() {
+	dir := s.T().TempDir()
+	root, err := filepath.Abs("..")
+
+	s.Require().NoError(err)
+
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.21\n\nrequire github.com/j7mbo/goij v0.0.0\n\n" +
+			"replace github.com/j7mbo/goij => " + root + "\n",
+		"store/registry/Store.go": "package registry\n\ntype Store struct{}\n",
+		"error/Error.go":          "package error\n\ntype Error struct{}\n",
+		"wiring/doc.go":           "package wiring\n",
+	}
+
+	for path, contents := range files {
+		s.Require().NoError(os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0755))
+		s.Require().NoError(os.WriteFile(filepath.Join(dir, path), []byte(contents), 0644))
+	}
+
+	gen := TypeRegistry.NewAutoRegistryGenerator(TypeRegistry.AutoGeneratedRegistryWriter{})
+
+	s.Require().NoError(gen.GenerateFile(filepath.Join(dir, "wiring/Registry.go"), TypeRegistry.Options{Dir: dir}))
+
+	registry, err := os.ReadFile(filepath.Join(dir, "wiring/Registry.go"))
+
+	s.Require().NoError(err)
+
+	/* The result of the function is called registry, and error is predeclared, so neither can be an alias. */
+	s.Assert().Contains(string(registry), `registry2 "example.com/app/store/registry"`)
+	s.Assert().Contains(string(registry), `error2 "example.com/app/error"`)
+
+	vet := exec.Command("go", "vet", "./...")
+	vet.Dir = dir
+	vet.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+
+	output, err := vet.CombinedOutput()
+
+	s.Assert().NoError(err, string(output))
+
+	/* A path element that is only punctuation would be a blank import, which Go can't build, but it's still parsed. */
+	s.Require().NoError(os.MkdirAll(filepath.Join(dir, "~"), 0755))
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "~/Tilde.go"), []byte("package tilde\n\ntype Tilde struct{}\n"), 0644))
+
+	var blank bytes.Buffer
+
+	s.Require().NoError(gen.GenerateTo(&blank, TypeRegistry.Options{Dir: dir, PackageName: "wiring"}))
+	s.Assert().Contains(blank.String(), `pkg "example.com/app/~"`)
+	s.Assert().Contains(blank.String(), "Implementation: pkg.Tilde{}")
+	s.Assert().NotContains(blank.String(), "_.Tilde")
+}
+
+func (s *InjectorTestSuite) TestImportAliasesAreDerivedFromThePath() {
+	taken := func(alias string) bool { return alias == "service" }
+
+	s.Assert().Equal("redis", TypeRegistry.ImportAlias("github.com/go-redis/redis/v8", taken))
+	s.Assert().Equal("service2", TypeRegistry.ImportAlias("example.com/app/service", taken))
+	s.Assert().Equal("error2", TypeRegistry.ImportAlias("example.com/app/error", taken))
+	s.Assert().Equal("_2d", TypeRegistry.ImportAlias("example.com/app/2d", taken))
+	s.Assert().Equal("my_app", TypeRegistry.ImportAlias("example.com/my-app", taken))
+	s.Assert().Equal("pkg", TypeRegistry.ImportAlias("example.com/app/~", taken))
+}
+
+func (s *InjectorTestSuite) TestRegistryIsGeneratedToAWriterWithOptions() {
+	gen := TypeRegistry.NewAutoRegistryGenerator(TypeRegistry.AutoGeneratedRegistryWriter{})
+	options := TypeRegistry.Options{
+		Dir:          "fixtures",
+		Exclude:      []string{"fixtures/registry", "fixtures/constructors"},
+		PackageName:  "wiring",
+		FunctionName: "GetFixturesRegistry",
+		BuildTags:    "!production && linux",
+	}
+
+	var first, second bytes.Buffer
+
+	s.Require().NoError(gen.GenerateTo(&first, options))
+	s.Require().NoError(gen.GenerateTo(&second, options))
+
+	s.Assert().Equal(first.String(), second.String())
+	s.Assert().True(strings.HasPrefix(first.String(), "//go:build !production && linux\n\npackage wiring\n"))
+	s.Assert().Contains(first.String(), "func GetFixturesRegistry() (registry TypeRegistry.Registry) {")
+	s.Assert().Equal(1, strings.Count(first.String(), `Name: "github.com/j7mbo/goij/test/fixtures.Pool", Implementation:`))
+
+	/* The generated registry of the fixtures is up to date with them. */
+	var registry bytes.Buffer
+
+	s.Require().NoError(gen.GenerateTo(&registry, TypeRegistry.Options{
+		Dir: "fixtures", Exclude: options.Exclude, PackageName: "registry",
+	}))
+
+	existing, err := os.ReadFile("fixtures/registry/Registry.go")
+
+	s.Require().NoError(err)
+	s.Assert().Equal(string(existing), registry.String())
+}
+
+func (s *InjectorTestSuite) TestRegistryGenerationReturnsErrors() {
+	gen := TypeRegistry.NewAutoRegistryGenerator(TypeRegistry.AutoGeneratedRegistryWriter{})
+
+	err := gen.GenerateTo(io.Discard, TypeRegistry.Options{Dir: filepath.Join(s.T().TempDir(), "missing")})
+
+	s.Require().Error(err)
+	s.Assert().Contains(err.Error(), "directory reading error")
+
+	err = gen.GenerateTo(io.Discard, TypeRegistry.Options{Dir: "fixtures", BuildTags: "linux &&"})
+
+	s.Require().Error(err)
+	s.Assert().Contains(err.Error(), "Unable to use build tags: 'linux &&'")
+
+	err = gen.GenerateTo(io.Discard, TypeRegistry.Options{Dir: "fixtures", PackageName: "my-registry"})
+
+	s.Require().Error(err)
+	s.Assert().Contains(err.Error(), "they must be identifiers")
+}
+
+func (s *InjectorTestSuite) TestRegistryCheckListsWhatChanged() {
+	dir := s.T().TempDir()
+
+	files := map[string]string{
+		"go.mod":            "module example.com/app\n",
+		"service/Server.go": "package service\n\ntype Server struct{}\n\nfunc NewServer(port int) *Server { return nil }\n",
+	}
+
+	for path, contents := range files {
+		s.Require().NoError(os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0755))
+		s.Require().NoError(os.WriteFile(filepath.Join(dir, path), []byte(contents), 0644))
+	}
+
+	gen := TypeRegistry.NewAutoRegistryGenerator(TypeRegistry.AutoGeneratedRegistryWriter{})
+	file := filepath.Join(dir, "Registry.go")
+	options := TypeRegistry.Options{Dir: dir}
+
+	s.Require().NoError(gen.GenerateFile(file, options))
+
+	difference, err := gen.Check(file, options)
+
+	s.Require().NoError(err)
+	s.Assert().Nil(difference)
+
+	s.Require().NoError(os.WriteFile(
+		filepath.Join(dir, "service/Server.go"),
+		[]byte("package service\n\ntype Server struct{}\ntype Client struct{}\n\nfunc NewClient() *Client { return nil }\n"),
+		0644,
+	))
+
+	difference, err = gen.Check(file, options)
+
+	s.Require().NoError(err)
+	s.Require().NotNil(difference)
+	s.Assert().Equal([]TypeRegistry.RegistryEntry{
+		{Kind: "struct", Name: "example.com/app/service.Client"},
+		{Kind: "factory", Name: "example.com/app/service.Client <- example.com/app/service.NewClient"},
+	}, difference.Added)
+	s.Assert().Equal([]TypeRegistry.RegistryEntry{
+		{Kind: "factory arguments", Name: "example.com/app/service.NewServer(port)"},
+		{Kind: "factory", Name: "example.com/app/service.Server <- example.com/app/service.NewServer"},
+	}, difference.Removed)
+	s.Assert().Contains(difference.String(), "  + struct example.com/app/service.Client\n")
+	s.Assert().Contains(difference.String(), "  - factory example.com/app/service.Server <- example.com/app/service.NewServer\n")
+
+	/* Registries written before the aliases were derived from the import path have the same entries. */
+	existing := `package main
+
+import "github.com/j7mbo/goij/src/TypeRegistry"
+import KZgmCUpd "example.com/app/service"
+
+func GetRegistry() (registry TypeRegistry.Registry) {
+    registry.RegistryStructs = append(registry.RegistryStructs, TypeRegistry.RegistryStruct{ Name: "example.com/app/service.Server", Implementation: KZgmCUpd.Server{}})
+    registry.RegistryStructs = append(registry.RegistryStructs, TypeRegistry.RegistryStruct{ Name: "example.com/app/service.Client", Implementation: KZgmCUpd.Client{}})
+    registry.RegistryFactories = append(registry.RegistryFactories, TypeRegistry.RegistryFactory{ Name: "example.com/app/service.Client", Implementations: []interface{}{ KZgmCUpd.NewClient }})
+
+    return
+}
+`
+
+	var generated bytes.Buffer
+
+	s.Require().NoError(gen.GenerateTo(&generated, options))
+
+	difference, err = TypeRegistry.CompareRegistries([]byte(existing), generated.Bytes())
+
+	s.Require().NoError(err)
+	s.Assert().False(difference.HasEntries())
+}
+
+func (s *InjectorTestSuite) TestWatchRegeneratesTheRegistryWhenFilesChange() {
+	dir := s.T().TempDir()
+
+	files := map[string]string{
+		"go.mod":              "module example.com/app\n",
+		"service/Server.go":   "package service\n\ntype Server struct{}\n",
+		"store/doc.go":        "package store\n",
+		"vendor/lib/Thing.go": "package lib\n\ntype Thing struct{}\n",
+	}
+
+	for path, contents := range files {
+		s.Require().NoError(os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0755))
+		s.Require().NoError(os.WriteFile(filepath.Join(dir, path), []byte(contents), 0644))
+	}
+
+	var progress TypeRegistry.Progress
+
+	gen := TypeRegistry.NewAutoRegistryGenerator(TypeRegistry.AutoGeneratedRegistryWriter{})
+	file := filepath.Join(dir, "Registry.go")
+	options := TypeRegistry.Options{
+		Dir:      dir,
+		Exclude:  []string{filepath.Join(dir, "vendor")},
+		Progress: func(read TypeRegistry.Progress) { progress = read },
+	}
+	events := make(chan TypeRegistry.WatchEvent, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() {
+		done <- gen.Watch(ctx, file, options, TypeRegistry.WatchOptions{
+			Interval: 5 * time.Millisecond, Debounce: 100 * time.Millisecond,
+		}, func(event TypeRegistry.WatchEvent) { events <- event })
+	}()
+
+	nextEvent := func() TypeRegistry.WatchEvent {
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(5 * time.Second):
+			s.FailNow("The registry was not regenerated")
+		}
+
+		return TypeRegistry.WatchEvent{}
+	}
+
+	event := nextEvent()
+
+	s.Require().NoError(event.Err)
+	s.Assert().Empty(event.Files)
+	s.Assert().True(event.Written)
+	s.Assert().Equal(
+		[]TypeRegistry.RegistryEntry{{Kind: "struct", Name: "example.com/app/service.Server"}}, event.Difference.Added,
+	)
+
+	/* Changes in excluded directories are ignored, and a burst of changes regenerates the registry once. */
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "vendor/lib/Other.go"), []byte("package lib\n"), 0644))
+	s.Require().NoError(os.WriteFile(
+		filepath.Join(dir, "service/Client.go"), []byte("package service\n\ntype Client struct{}\n"), 0644,
+	))
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "service/notes.txt"), []byte("not go"), 0644))
+	s.Require().NoError(os.Remove(filepath.Join(dir, "service/Server.go")))
+
+	event = nextEvent()
+
+	s.Require().NoError(event.Err)
+	s.Assert().Equal([]string{"service/Client.go", "service/Server.go"}, event.Files)
+	s.Assert().Equal(
+		[]TypeRegistry.RegistryEntry{{Kind: "struct", Name: "example.com/app/service.Client"}}, event.Difference.Added,
+	)
+	s.Assert().Equal(
+		[]TypeRegistry.RegistryEntry{{Kind: "struct", Name: "example.com/app/service.Server"}}, event.Difference.Removed,
+	)
+	s.Assert().Contains(event.String(), "Changed: service/Client.go, service/Server.go, the registry was updated:\n")
+
+	/*
+		What was parsed is kept between regenerations without a cache file, so only the directories that changed are
+		parsed: service/ and the one the registry was written to, but not store/.
+	*/
+	s.Assert().Equal(2, progress.Parsed)
+	s.Assert().Equal(1, progress.Cached)
+
+	registry, err := os.ReadFile(file)
+
+	s.Require().NoError(err)
+	s.Assert().Contains(string(registry), "service.Client{}")
+
+	/* Changes that don't change the registry don't write it. */
+	s.Require().NoError(os.WriteFile(
+		filepath.Join(dir, "service/Client.go"), []byte("package service\n\ntype Client struct{ Name string }\n"), 0644,
+	))
+
+	event = nextEvent()
+
+	s.Require().NoError(event.Err)
+	s.Assert().False(event.Difference.HasEntries())
+	s.Assert().False(event.Written)
+	s.Assert().Equal("Changed: service/Client.go, the registry is up to date\n", event.String())
+
+	/* A registry that was edited by hand is rewritten, even though the types in it are the same. */
+	s.Require().NoError(os.WriteFile(file, append(registry, []byte("// Edited\n")...), 0644))
+	s.Require().NoError(os.WriteFile(
+		filepath.Join(dir, "service/Client.go"), []byte("package service\n\ntype Client struct{}\n"), 0644,
+	))
+
+	event = nextEvent()
+
+	s.Require().NoError(event.Err)
+	s.Assert().False(event.Difference.HasEntries())
+	s.Assert().True(event.Written)
+	s.Assert().Equal(
+		"Changed: service/Client.go, the registry was rewritten:\n"+
+			"  The types are the same, but the package, function, build tags or formatting are different\n",
+		event.String(),
+	)
+
+	cancel()
+
+	s.Assert().NoError(<-done)
+	s.Assert().Empty(events)
+}
+
+func (s *InjectorTestSuite) TestWatchReportsDirectoriesThatCantBeReadAndCarriesOn() {
+	root := s.T().TempDir()
+	dir := filepath.Join(root, "app")
+	moved := filepath.Join(root, "moved")
+
+	s.Require().NoError(os.MkdirAll(filepath.Join(dir, "service"), 0755))
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0644))
+	s.Require().NoError(os.WriteFile(
+		filepath.Join(dir, "service/Server.go"), []byte("package service\n\ntype Server struct{}\n"), 0644,
+	))
+
+	gen := TypeRegistry.NewAutoRegistryGenerator(TypeRegistry.AutoGeneratedRegistryWriter{})
+	file := filepath.Join(dir, "Registry.go")
+	events := make(chan TypeRegistry.WatchEvent, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() {
+		done <- gen.Watch(ctx, file, TypeRegistry.Options{Dir: dir}, TypeRegistry.WatchOptions{
+			Interval: 5 * time.Millisecond, Debounce: 50 * time.Millisecond,
+		}, func(event TypeRegistry.WatchEvent) { events <- event })
+	}()
+
+	nextEvent := func() TypeRegistry.WatchEvent {
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(5 * time.Second):
+			s.FailNow("Nothing was reported")
+		}
+
+		return TypeRegistry.WatchEvent{}
+	}
+
+	s.Require().NoError(nextEvent().Err)
+
+	/* The directory disappearing is reported once, rather than on every poll, and watching carries on. */
+	s.Require().NoError(os.Rename(dir, moved))
+
+	event := nextEvent()
+
+	s.Require().Error(event.Err)
+	s.Assert().True(strings.HasPrefix(event.String(), "Unable to generate the registry: Unable to watch: "))
+
+	time.Sleep(50 * time.Millisecond)
+
+	s.Assert().Empty(events)
+	s.Require().NoError(os.Rename(moved, dir))
+	s.Require().NoError(os.WriteFile(
+		filepath.Join(dir, "service/Client.go"), []byte("package service\n\ntype Client struct{}\n"), 0644,
+	))
+
+	event = nextEvent()
+
+	s.Require().NoError(event.Err)
+	s.Assert().Equal(
+		[]TypeRegistry.RegistryEntry{{Kind: "struct", Name: "example.com/app/service.Client"}}, event.Difference.Added,
+	)
+
+	cancel()
+
+	s.Assert().NoError(<-done)
+}
+
+func (s *InjectorTestSuite) TestRegistryGenerationOnlyParsesChangedDirectoriesWithACache() {
+	dir := s.T().TempDir()
+
+	files := map[string]string{
+		"go.mod":            "module example.com/app\n",
+		"service/Server.go": "package service\n\ntype Server struct{}\n\nfunc NewServer(port int) *Server { return nil }\n",
+		"store/Store.go": "package store\n\ntype Store interface{ Get() }\ntype MemoryStore struct{}\n\n" +
+			"func (MemoryStore) Get() {}\n",
+		"empty/README.md": "No Go files here.\n",
+	}
+
+	for path, contents := range files {
+		s.Require().NoError(os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0755))
+		s.Require().NoError(os.WriteFile(filepath.Join(dir, path), []byte(contents), 0644))
+	}
+
+	gen := TypeRegistry.NewAutoRegistryGenerator(TypeRegistry.AutoGeneratedRegistryWriter{})
+	cacheFile := filepath.Join(s.T().TempDir(), "cache.json")
+
+	generate := func(cacheFile string) (string, TypeRegistry.Progress) {
+		var registry bytes.Buffer
+		var progress TypeRegistry.Progress
+
+		reports := 0
+
+		s.Require().NoError(gen.GenerateTo(&registry, TypeRegistry.Options{
+			Dir:       dir,
+			CacheFile: cacheFile,
+			Progress: func(latest TypeRegistry.Progress) {
+				reports++
+				progress = latest
+			},
+		}))
+
+		s.Assert().Equal(progress.Total, reports)
+		s.Assert().Equal(progress.Total, progress.Read)
+
+		return registry.String(), progress
+	}
+
+	uncached, _ := generate("")
+	registry, progress := generate(cacheFile)
+
+	s.Assert().Equal(uncached, registry)
+	s.Assert().Equal(4, progress.Total)
+	s.Assert().Equal(TypeRegistry.Progress{Read: 4, Total: 4, Parsed: 4, Elapsed: progress.Elapsed}, progress)
+
+	registry, progress = generate(cacheFile)
+
+	s.Assert().Equal(uncached, registry)
+	s.Assert().Equal(TypeRegistry.Progress{Read: 4, Total: 4, Cached: 4, Elapsed: progress.Elapsed}, progress)
+
+	/* Only the changed package is parsed again, and what was parsed from it replaces what was cached. */
+	s.Require().NoError(os.WriteFile(
+		filepath.Join(dir, "service/Client.go"), []byte("package service\n\ntype Client struct{}\n"), 0644,
+	))
+
+	registry, progress = generate(cacheFile)
+
+	s.Assert().Equal(TypeRegistry.Progress{Read: 4, Total: 4, Parsed: 1, Cached: 3, Elapsed: progress.Elapsed}, progress)
+	s.Assert().Contains(registry, `Name: "example.com/app/service.Client"`)
+	s.Assert().Contains(registry, `Name: "example.com/app/store.MemoryStore"`)
+
+	/* A cache file that can't be read is ignored, and written again. */
+	s.Require().NoError(os.WriteFile(cacheFile, []byte("{not json"), 0644))
+
+	_, progress = generate(cacheFile)
+
+	s.Assert().Equal(4, progress.Parsed)
+
+	_, progress = generate(cacheFile)
+
+	s.Assert().Equal(4, progress.Cached)
+}
+
+func (s *InjectorTestSuite) TestRegistryHasFactoriesForEveryKindOfReturnType() {
+	dir := s.T().TempDir()
+
+	files := map[string]string{
+		"go.mod": "module example.com/app\n",
+		"service/Service.go": `package service
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/x/go-redis/v8"
+	"gopkg.in/yaml.v2"
+)
+
+type Store interface{ Get() string }
+type DB struct{}
+type Conn struct{}
+type Item struct{}
+type Box[T any] struct{ Value T }
+
+func NewStore() Store { return nil }
+func NewLogger() *log.Logger { return nil }
+func NewDB() (*DB, error) { return nil, nil }
+func NewConn() (conn *Conn, close func(), err error) { return nil, nil, nil }
+func NewIntBox() *Box[int] { return nil }
+func NewItemBox() Box[*Item] { return Box[*Item]{} }
+func NewDecoder() *yaml.Decoder { return nil }
+func NewClient() *redis.Client { return nil }
+func NewPair() (*Item, *Item) { return nil, nil }
+func NewGeneric[T any]() *Box[T] { return nil }
+`,
+	}
+
+	for path, contents := range files {
+		s.Require().NoError(os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0755))
+		s.Require().NoError(os.WriteFile(filepath.Join(dir, path), []byte(contents), 0644))
+	}
+
+	gen := TypeRegistry.NewAutoRegistryGenerator(TypeRegistry.AutoGeneratedRegistryWriter{})
+	gen.Generate(filepath.Join(dir, "Registry.go"), dir)
+
+	registry, err := os.ReadFile(filepath.Join(dir, "Registry.go"))
+
+	s.Require().NoError(err)
+
+	for _, name := range []string{
+		"example.com/app/service.Store",
+		"github.com/sirupsen/logrus.Logger",
+		"example.com/app/service.DB",
+		"example.com/app/service.Conn",
+		"example.com/app/service.Box[int]",
+		"example.com/app/service.Box[*example.com/app/service.Item]",
+		"gopkg.in/yaml.v2.Decoder",
+		"github.com/x/go-redis/v8.Client",
+	} {
+		s.Assert().Contains(string(registry), fmt.Sprintf(`RegistryFactory{Name: "%s"`, name))
+	}
+
+	s.Assert().NotContains(string(registry), "NewPair")
+	s.Assert().NotContains(string(registry), "NewGeneric")
+	s.Assert().NotContains(string(registry), "service.Box\"")
+}
+
+func (s *InjectorTestSuite) TestFactoriesForInstantiatedGenericTypesAreUsed() {
+	registry := TypeRegistry.Registry{
+		RegistryStructs: []TypeRegistry.RegistryStruct{
+			{Name: "github.com/j7mbo/goij/test.testObjWithGenericDep", Implementation: testObjWithGenericDep{}},
+		},
+		RegistryFactories: []TypeRegistry.RegistryFactory{
+			{
+				Name:            "github.com/j7mbo/goij/test.testGenericBox[time.Duration]",
+				Implementations: []interface{}{newTestDurationBox},
+			},
+		},
+	}
+
+	obj := Goij.NewInjector(TypeRegistry.New(registry), nil).Make("testObjWithGenericDep").(*testObjWithGenericDep)
+
+	s.Assert().Equal(time.Second, obj.Box.Value)
+}
+
+/* An injector for the fixtures application, with it's configuration. */
+func newFixturesInjector(s *InjectorTestSuite) Goij.Injector {
+	ij := Goij.NewInjector(TypeRegistry.New(fixturesregistry.GetRegistry()), nil)
+
+	config, err := os.Open("fixtures/config.json")
+
+	s.Require().NoError(err)
+
+	defer func() { _ = config.Close() }()
+
+	s.Require().NoError(ij.LoadConfig(config))
+
+	return ij
+}
+
+/* Runs the given function and returns the error it panicked with, if any. */
+func recoverError(f func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err, _ = r.(error)
+		}
+	}()
+
+	f()
+
+	return nil
+}
+
 /* Types must be declared here to be found, can't add methods to a type within a function. */
 type testInterface interface{ AMethod() }
 type testObj struct{}
@@ -1361,12 +3484,58 @@ type testObjWithInjectorDep struct{ Injector Goij.Injector }
 type testObjWithPointerInt struct{ Int *int64 }
 type testParentObjForObjWithPointerInt struct{ Obj testObjWithPointerInt }
 
+type testObjWithTimeouts struct {
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	Retries      int
+}
+
+type testPort int
+
+type testObjWithPort struct{ Port testPort }
+
+type testObjWithConvertibleFields struct {
+	Port     testPort
+	Timeout  time.Duration
+	Endpoint *url.URL
+	Weights  []float64
+	Size     int64
+	Small    int8
+	IP       net.IP
+}
+
+type testCircularObj struct{ Dep *testCircularDep }
+type testCircularDep struct{ Obj *testCircularObj }
+
+type testObjWithEnvTags struct {
+	Host    string        `env:"GOIJ_TEST_HOST,required"`
+	Port    int           `env:"GOIJ_TEST_PORT,default=8080"`
+	Timeout time.Duration `env:"GOIJ_TEST_TIMEOUT"`
+	Tags    []string      `env:"GOIJ_TEST_TAGS"`
+}
+
+type testObjWithEnvDefaults struct {
+	Tags []string `env:"GOIJ_TEST_DEFAULT_TAGS,required,default=one,two"`
+}
+
 func (*testObj) AMethod()                       {}
 func (*testObj2) AMethod()                      {}
 func (t *testObjWithInt) IntMethod() int        { return t.Int }
 func (*testObjWithInt) ReturnInt() int          { return 42 }
 func (testObjWithInt) ValueReceiverMethod() int { return 42 }
 
+func (*testObjWithInt) IsNil(i testInterface) bool {
+	return i == nil
+}
+
+func (*testObjWithInt) Sum(ints ...int) (sum int) {
+	for _, i := range ints {
+		sum += i
+	}
+
+	return sum
+}
+
 type testInterfaceForObjWithInt interface {
 	IntMethod() int
 }
@@ -1471,3 +3640,43 @@ type ParentObjForObjWithSharedDep struct {
 func NewObjWithSharedDep(TestObjWithInt *testObjWithInt) ObjWithSharedDep {
 	return ObjWithSharedDep{TestObjWithInt: TestObjWithInt}
 }
+
+type testGenericBox[T any] struct{ Value T }
+
+type testObjWithGenericDep struct {
+	Box *testGenericBox[time.Duration]
+}
+
+func newTestDurationBox() *testGenericBox[time.Duration] {
+	return &testGenericBox[time.Duration]{Value: time.Second}
+}
+
+/* Records log messages formatted as text, for the levels from it's level. */
+type recordingLogger struct {
+	level   Logger.Level
+	entries []recordedLog
+}
+
+type recordedLog struct {
+	level Logger.Level
+	msg   string
+	text  string
+}
+
+func (l *recordingLogger) Enabled(level Logger.Level) bool {
+	return level >= l.level
+}
+
+func (l *recordingLogger) Log(level Logger.Level, msg string, fields ...Logger.Field) {
+	l.entries = append(l.entries, recordedLog{level: level, msg: msg, text: Logger.Format(level, msg, fields...)})
+}
+
+func (l *recordingLogger) find(msg string) *recordedLog {
+	for i := range l.entries {
+		if l.entries[i].msg == msg {
+			return &l.entries[i]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+/* Package fixtures is a small application, with a generated registry, for the tests of the generated constructors. */
+package fixtures
+
+import (
+	"errors"
+	"time"
+)
+
+type Users interface {
+	Find(id int) string
+}
+
+type MySQLUsers struct {
+	DB    *Pool
+	Table string
+}
+
+type MemoryUsers struct {
+	Names []string
+}
+
+type Connection struct {
+	DSN     string
+	Timeout time.Duration
+}
+
+type Pool struct {
+	Connection Connection
+	Size       int
+	Closed     bool
+}
+
+type Cache struct {
+	Size int
+}
+
+type Settings struct {
+	Debug bool
+	Ratio float64
+	Tags  []string
+}
+
+type IndexController struct {
+	Users    Users
+	Cache    *Cache
+	Settings Settings
+	Port     int
+	Name     string
+}
+
+func (u *MySQLUsers) Find(id int) string {
+	return u.Table
+}
+
+func (u *MemoryUsers) Find(id int) string {
+	return u.Names[id]
+}
+
+func NewPool(connection Connection, size int) (*Pool, func(), error) {
+	if connection.DSN == "" {
+		return nil, nil, errors.New("no DSN to connect to")
+	}
+
+	pool := &Pool{Connection: connection, Size: size + 1}
+
+	return pool, func() { pool.Closed = true }, nil
+}
@@ -0,0 +1,56 @@
+// Code generated by Goij from the type registry. DO NOT EDIT.
+
+package constructors
+
+import (
+	fixtures "github.com/j7mbo/goij/test/fixtures"
+	time "time"
+)
+
+/* ConstructIndexController makes "github.com/j7mbo/goij/test/fixtures.IndexController" the same way as Make(). */
+func ConstructIndexController(sharedCache *fixtures.Cache) (*fixtures.IndexController, func(), error) {
+	v1 := &fixtures.Connection{}
+	v1.DSN = "mysql://localhost/app"
+	v1.Timeout = time.Duration(5000000000)
+	var v2 int
+	v3, cleanup3, err := fixtures.NewPool(*v1, v2)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v4 := &fixtures.MySQLUsers{}
+	v4.DB = v3
+	v4.Table = "users"
+	v5 := *sharedCache
+	v6 := &fixtures.Settings{}
+	v6.Debug = true
+	v6.Ratio = float64(0.5)
+	v6.Tags = []string{"a", "b"}
+	v7 := &fixtures.IndexController{}
+	v7.Users = v4
+	v7.Cache = &v5
+	v7.Settings = *v6
+	v7.Port = 8080
+
+	return v7, cleanup3, nil
+}
+
+/* ConstructUsers makes "github.com/j7mbo/goij/test/fixtures.Users" the same way as Make(). */
+func ConstructUsers() (fixtures.Users, func(), error) {
+	v1 := &fixtures.Connection{}
+	v1.DSN = "mysql://localhost/app"
+	v1.Timeout = time.Duration(5000000000)
+	var v2 int
+	v3, cleanup3, err := fixtures.NewPool(*v1, v2)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v4 := &fixtures.MySQLUsers{}
+	v4.DB = v3
+	v4.Table = "users"
+
+	return v4, cleanup3, nil
+}
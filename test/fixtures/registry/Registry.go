@@ -0,0 +1,21 @@
+package registry
+
+import (
+	"github.com/j7mbo/goij/src/TypeRegistry"
+	fixtures "github.com/j7mbo/goij/test/fixtures"
+)
+
+func GetRegistry() (registry TypeRegistry.Registry) {
+	registry.RegistryStructs = append(registry.RegistryStructs, TypeRegistry.RegistryStruct{Name: "github.com/j7mbo/goij/test/fixtures.Cache", Implementation: fixtures.Cache{}})
+	registry.RegistryStructs = append(registry.RegistryStructs, TypeRegistry.RegistryStruct{Name: "github.com/j7mbo/goij/test/fixtures.Connection", Implementation: fixtures.Connection{}})
+	registry.RegistryStructs = append(registry.RegistryStructs, TypeRegistry.RegistryStruct{Name: "github.com/j7mbo/goij/test/fixtures.IndexController", Implementation: fixtures.IndexController{}})
+	registry.RegistryStructs = append(registry.RegistryStructs, TypeRegistry.RegistryStruct{Name: "github.com/j7mbo/goij/test/fixtures.MemoryUsers", Implementation: fixtures.MemoryUsers{}})
+	registry.RegistryStructs = append(registry.RegistryStructs, TypeRegistry.RegistryStruct{Name: "github.com/j7mbo/goij/test/fixtures.MySQLUsers", Implementation: fixtures.MySQLUsers{}})
+	registry.RegistryStructs = append(registry.RegistryStructs, TypeRegistry.RegistryStruct{Name: "github.com/j7mbo/goij/test/fixtures.Pool", Implementation: fixtures.Pool{}})
+	registry.RegistryStructs = append(registry.RegistryStructs, TypeRegistry.RegistryStruct{Name: "github.com/j7mbo/goij/test/fixtures.Settings", Implementation: fixtures.Settings{}})
+	registry.RegistryInterfaces = append(registry.RegistryInterfaces, TypeRegistry.RegistryInterface{Name: "github.com/j7mbo/goij/test/fixtures.Users", Implementation: (*fixtures.Users)(nil)})
+	registry.RegistryFactoryArguments = append(registry.RegistryFactoryArguments, TypeRegistry.RegistryFactoryArguments{Name: "github.com/j7mbo/goij/test/fixtures.NewPool", Arguments: []string{"connection", "size"}})
+	registry.RegistryFactories = append(registry.RegistryFactories, TypeRegistry.RegistryFactory{Name: "github.com/j7mbo/goij/test/fixtures.Pool", Implementations: []interface{}{fixtures.NewPool}})
+
+	return
+}